@@ -0,0 +1,71 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapitest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := pokeapitest.NewServer(t)
+	baseURL := fixtures.BaseURL()
+
+	recorder := NewClient(WithBaseURL(baseURL), WithRecording(dir))
+	defer recorder.Close()
+	ctx := context.Background()
+	if _, err := recorder.GetPokemon(ctx, "pikachu"); err != nil {
+		t.Fatalf("Recording GetPokemon returned an error: %v", err)
+	}
+	if _, err := recorder.GetLocationAreas(ctx, 0, 20); err != nil {
+		t.Fatalf("Recording GetLocationAreas returned an error: %v", err)
+	}
+	if _, err := recorder.GetPokemon(ctx, "missingno"); !errors.As(err, &ResourceNotFoundError{}) {
+		t.Fatalf("Expected a ResourceNotFoundError while recording, found: %v", err)
+	}
+	fixtures.Close()
+
+	replayer := NewClient(WithBaseURL(baseURL), WithReplay(dir), WithRetryPolicy(NoRetries))
+	defer replayer.Close()
+
+	pokemon, err := replayer.GetPokemon(ctx, "pikachu")
+	if err != nil {
+		t.Fatalf("Replaying GetPokemon returned an error: %v", err)
+	}
+	if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+		t.Errorf("Unexpected Pokemon replayed: %+v", pokemon)
+	}
+
+	areas, err := replayer.GetLocationAreas(ctx, 0, 20)
+	if err != nil {
+		t.Fatalf("Replaying GetLocationAreas returned an error: %v", err)
+	}
+	if len(areas.Results) != 20 || areas.Results[0].Name != "canalave-city-area" {
+		t.Errorf("Unexpected location-areas replayed: %+v", areas.Results)
+	}
+
+	if _, err := replayer.GetPokemon(ctx, "missingno"); !errors.As(err, &ResourceNotFoundError{}) {
+		t.Errorf("Expected the recorded 404 to replay as a ResourceNotFoundError, found: %v", err)
+	}
+}
+
+func TestReplayUnrecorded(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := NewClient(WithBaseURL(fixtures.BaseURL()), WithReplay(t.TempDir()))
+	defer client.Close()
+
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	var unrecorded UnrecordedRequestError
+	if !errors.As(err, &unrecorded) {
+		t.Fatalf("Expected an UnrecordedRequestError, found: %v", err)
+	}
+	if unrecorded.URL != fixtures.URL+"/pokemon/pikachu" {
+		t.Errorf("Wrong URL reported.\n\tExpected: %s\n\tFound: %s", fixtures.URL+"/pokemon/pikachu", unrecorded.URL)
+	}
+	if n := fixtures.Requests("/pokemon/pikachu"); n != 0 {
+		t.Errorf("Replay should never reach the network, made %d requests", n)
+	}
+}
@@ -0,0 +1,174 @@
+package pokeapi
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokecache"
+)
+
+const (
+	DefaultBaseURL   = "https://pokeapi.co/api/v2/"
+	DefaultUserAgent = "pokedexcli"
+	DefaultTimeout   = 10 * time.Second
+	DefaultCacheTTL  = 5 * time.Second
+
+	// How long listing pages and individual resources stay fresh in memory.
+	DefaultListTTL     = 5 * time.Minute
+	DefaultResourceTTL = time.Hour
+	DefaultNotFoundTTL = time.Minute
+)
+
+/* Client
+ * A Client fetches resources from a PokeAPI instance, caching the raw
+ * responses in its pokecache.Cache. The zero value is not usable; build one
+ * with NewClient.
+ */
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	timeout     *time.Duration // Overrides httpClient's timeout, if set
+	userAgent   string
+	cache       *pokecache.Cache
+	retryPolicy RetryPolicy
+	limiter     *rateLimiter
+
+	listTTL     time.Duration
+	resourceTTL time.Duration
+	notFoundTTL time.Duration
+	staleWindow time.Duration
+	refreshing  sync.Map // Set of url.URLs being revalidated in the background
+	inflight    inflight
+
+	recordDir string // Cassette to record responses to, if any
+	replayDir string // Cassette to replay responses from, if any
+}
+
+// An Option configures a Client built by NewClient.
+type Option func(*Client)
+
+// WithBaseURL points the Client at a PokeAPI instance other than pokeapi.co,
+// such as a local mirror or an httptest server.
+func WithBaseURL(baseURL *url.URL) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient replaces the *http.Client used to make requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout of the Client's *http.Client. An *http.Client
+// passed in WithHTTPClient is copied rather than modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = &timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithCache replaces the Client's response cache.
+func WithCache(cache *pokecache.Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithListTTL sets how long listing pages, such as GetLocationAreas, are
+// cached in memory.
+func WithListTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.listTTL = ttl
+	}
+}
+
+// WithResourceTTL sets how long individual resources, such as GetPokemon,
+// are cached in memory.
+func WithResourceTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.resourceTTL = ttl
+	}
+}
+
+// WithNotFoundTTL sets how long a 404 response is remembered, so that repeat
+// lookups of a missing resource don't reach PokeAPI. A ttl <= 0 disables
+// caching of 404s.
+func WithNotFoundTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.notFoundTTL = ttl
+	}
+}
+
+/* WithStaleWhileRevalidate
+ * Serves cached responses for up to `window` after they expire, refreshing
+ * them in the background. If the Client's cache is passed in WithCache, it
+ * must be built with a pokecache.WithStaleWindow of its own for stale
+ * entries to be kept.
+ */
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(c *Client) {
+		c.staleWindow = window
+	}
+}
+
+/* NewClient
+ * Builds a Client for https://pokeapi.co/api/v2/ using its own *http.Client
+ * with a DefaultTimeout, the DefaultRetryPolicy, a limit of DefaultRateLimit
+ * requests per second, and an in-memory cache that keeps listing pages for
+ * DefaultListTTL, resources for DefaultResourceTTL and 404s for
+ * DefaultNotFoundTTL. Any of these may be overridden with Options.
+ */
+func NewClient(opts ...Option) *Client {
+	baseURL, _ := url.Parse(DefaultBaseURL)
+
+	c := &Client{
+		baseURL:     baseURL,
+		httpClient:  &http.Client{Timeout: DefaultTimeout},
+		userAgent:   DefaultUserAgent,
+		retryPolicy: DefaultRetryPolicy,
+		limiter:     newRateLimiter(DefaultRateLimit, DefaultRateBurst),
+		listTTL:     DefaultListTTL,
+		resourceTTL: DefaultResourceTTL,
+		notFoundTTL: DefaultNotFoundTTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.timeout != nil {
+		httpClient := *c.httpClient
+		httpClient.Timeout = *c.timeout
+		c.httpClient = &httpClient
+	}
+	c.httpClient = c.cassetteClient(c.httpClient)
+
+	if c.cache == nil {
+		c.cache = pokecache.NewCache(DefaultCacheTTL, pokecache.WithStaleWindow(c.staleWindow))
+	}
+	return c
+}
+
+// Cache returns the Client's response cache.
+func (c *Client) Cache() *pokecache.Cache {
+	return c.cache
+}
+
+// Close releases the Client's cache, including one passed in WithCache.
+func (c *Client) Close() {
+	c.cache.Close()
+}
+
+// DefaultClient is the Client used by the package-level Get* functions.
+var DefaultClient = NewClient()
@@ -0,0 +1,284 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Builds a Client pointed at the given httptest server.
+func newTestClient(t *testing.T, server *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Error parsing test server URL: %v", err)
+	}
+	client := NewClient(append([]Option{WithBaseURL(baseURL)}, opts...)...)
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestClientOptions(t *testing.T) {
+	var gotPath, gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAgent = r.URL.Path, r.UserAgent()
+		w.Write([]byte(`{"name": "pikachu", "base_experience": 112}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, WithUserAgent("pokedexcli-test"))
+	pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon returned an error: %v", err)
+	}
+
+	if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+		t.Errorf("Unexpected Pokemon decoded: %+v", pokemon)
+	}
+	if gotPath != "/pokemon/pikachu" {
+		t.Errorf("Request went to the wrong path.\n\tExpected: %s\n\tFound: %s", "/pokemon/pikachu", gotPath)
+	}
+	if gotAgent != "pokedexcli-test" {
+		t.Errorf("Request sent the wrong User-Agent.\n\tExpected: %s\n\tFound: %s", "pokedexcli-test", gotAgent)
+	}
+}
+
+func TestClientTimeoutOption(t *testing.T) {
+	owned := &http.Client{Timeout: time.Minute}
+
+	for _, opts := range [][]Option{
+		{WithHTTPClient(owned), WithTimeout(time.Second)},
+		{WithTimeout(time.Second), WithHTTPClient(owned)},
+	} {
+		client := NewClient(opts...)
+		client.Close()
+		if client.httpClient.Timeout != time.Second {
+			t.Errorf("WithTimeout was not applied, timeout is %v", client.httpClient.Timeout)
+		}
+	}
+	if owned.Timeout != time.Minute {
+		t.Errorf("WithTimeout modified the caller's *http.Client, timeout is %v", owned.Timeout)
+	}
+}
+
+func TestClientCachesResponses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	for range 3 {
+		if _, err := client.GetPokemon(context.Background(), "pikachu"); err != nil {
+			t.Fatalf("GetPokemon returned an error: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request to reach the server, found %d", requests)
+	}
+}
+
+func TestClientNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	_, err := client.GetLocationAreas(context.Background(), 0, 20)
+	if _, ok := err.(ResourceNotFoundError); !ok {
+		t.Fatalf("Expected a ResourceNotFoundError, found: %v", err)
+	}
+}
+
+func TestClientNotFoundEveryEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	ctx := context.Background()
+	_, listErr := client.GetLocationAreas(ctx, 0, 20)
+	_, areaErr := client.GetLocationArea(ctx, "nowhere")
+	_, pokemonErr := client.GetPokemon(ctx, "missingno")
+
+	cases := []struct {
+		err  error
+		name string
+	}{
+		{listErr, "location-area"},
+		{areaErr, "nowhere"},
+		{pokemonErr, "missingno"},
+	}
+	for _, c := range cases {
+		var notFound ResourceNotFoundError
+		if !errors.As(c.err, &notFound) {
+			t.Errorf("Expected a ResourceNotFoundError for %s, found: %v", c.name, c.err)
+			continue
+		}
+		if notFound.ResourceName != c.name {
+			t.Errorf("Wrong resource name.\n\tExpected: %s\n\tFound: %s", c.name, notFound.ResourceName)
+		}
+	}
+}
+
+func TestGetLocationAreasInvalidArguments(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"count": 0, "results": []}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	cases := []struct {
+		offset, limit int
+		argument      string
+	}{
+		{-1, 20, "offset"},
+		{0, 0, "limit"},
+		{0, -5, "limit"},
+	}
+	for _, c := range cases {
+		_, err := client.GetLocationAreas(context.Background(), c.offset, c.limit)
+		var invalid InvalidArgumentError
+		if !errors.As(err, &invalid) {
+			t.Errorf("GetLocationAreas(%d, %d): expected an InvalidArgumentError, found: %v", c.offset, c.limit, err)
+			continue
+		}
+		if invalid.Argument != c.argument {
+			t.Errorf("Wrong argument rejected.\n\tExpected: %s\n\tFound: %s", c.argument, invalid.Argument)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("Invalid arguments should not make requests, made %d", requests)
+	}
+
+	if _, err := client.GetLocationAreas(context.Background(), 0, 1); err != nil {
+		t.Errorf("GetLocationAreas(0, 1) returned an error: %v", err)
+	}
+}
+
+func TestClientCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := newTestClient(t, server)
+	_, err := client.GetPokemon(ctx, "pikachu")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a context.Canceled error, found: %v", err)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name": "v%d"}`, requests.Add(1))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server,
+		WithResourceTTL(time.Millisecond),
+		WithStaleWhileRevalidate(time.Minute))
+	ctx := context.Background()
+
+	if pokemon, _ := client.GetPokemon(ctx, "pikachu"); pokemon.Name != "v1" {
+		t.Fatalf("Expected the first response, found %s", pokemon.Name)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The expired entry is served as-is while it is refreshed.
+	if pokemon, _ := client.GetPokemon(ctx, "pikachu"); pokemon.Name != "v1" {
+		t.Fatalf("Expected the stale response, found %s", pokemon.Name)
+	}
+
+	key := *client.baseURL.JoinPath("pokemon", "pikachu")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if entry, _ := client.cache.Lookup(key); string(entry.Val) == `{"name": "v2"}` {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Stale entry was never refreshed in the background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNegativeCaching(t *testing.T) {
+	testCases := []struct {
+		ttl      time.Duration
+		expected int32
+	}{
+		{DefaultNotFoundTTL, 1},
+		{0, 3},
+	}
+
+	for _, testCase := range testCases {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.NotFound(w, r)
+		}))
+		defer server.Close()
+
+		client := newTestClient(t, server, WithNotFoundTTL(testCase.ttl))
+		for range 3 {
+			_, err := client.GetPokemon(context.Background(), "pikchu")
+			if notFound, ok := err.(ResourceNotFoundError); !ok || notFound.ResourceName != "pikchu" {
+				t.Fatalf("Expected a ResourceNotFoundError for pikchu, found: %v", err)
+			}
+		}
+		if requests.Load() != testCase.expected {
+			t.Errorf("With a not-found TTL of %v, expected %d requests, found %d",
+				testCase.ttl, testCase.expected, requests.Load())
+		}
+	}
+}
+
+func TestConditionalRevalidation(t *testing.T) {
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	// A plain Client has no stale window, so expired entries must be kept
+	// for their validators alone.
+	client := newTestClient(t, server, WithResourceTTL(time.Millisecond))
+	ctx := context.Background()
+
+	for range 2 {
+		pokemon, err := client.GetPokemon(ctx, "pikachu")
+		if err != nil || pokemon.Name != "pikachu" {
+			t.Fatalf("GetPokemon returned %q, error %v", pokemon.Name, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if requests.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("Expected 2 requests, 1 of them revalidated. Found %d requests, %d revalidated",
+			requests.Load(), notModified.Load())
+	}
+	entry, ok := client.Cache().Lookup(*client.baseURL.JoinPath("pokemon", "pikachu"))
+	if !ok || entry.ETag != `"v1"` || string(entry.Val) != `{"name": "pikachu"}` {
+		t.Errorf("Revalidated entry lost its value or validators: %+v", entry)
+	}
+}
@@ -0,0 +1,80 @@
+package pokeapi
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+/* inflight
+ * Deduplicates concurrent fetches of the same url, keyed the same way as
+ * pokecache. The first caller starts the fetch and every caller that arrives
+ * before it finishes shares its result.
+ *
+ * The fetch runs with its own context, which is only cancelled once every
+ * caller waiting on it has given up, so one impatient caller can't fail the
+ * fetch for the rest. A fetch abandoned by every caller is forgotten
+ * immediately, so later callers never share its cancellation.
+ */
+type inflight struct {
+	sync.Mutex
+	calls map[url.URL]*call
+}
+
+type call struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	data    []byte
+	err     error
+}
+
+func (g *inflight) do(ctx context.Context, key url.URL, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g.Lock()
+	if g.calls == nil {
+		g.calls = make(map[url.URL]*call)
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+
+		go func() {
+			c.data, c.err = fn(fetchCtx)
+			cancel()
+
+			// The call may have been abandoned, and replaced by a new
+			// fetch of the same key, while fn wound down.
+			g.Lock()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+			g.Unlock()
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	g.Unlock()
+
+	select {
+	case <-c.done:
+		return c.data, c.err
+	case <-ctx.Done():
+		g.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Forget the call right away, so that callers arriving while it
+			// winds down start a fresh fetch instead of joining a cancelled
+			// one.
+			delete(g.calls, key)
+			c.cancel()
+		}
+		g.Unlock()
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,174 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Blocks until n callers are waiting on the in-flight fetch of key.
+func waitForWaiters(t *testing.T, g *inflight, key url.URL, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		g.Lock()
+		c, ok := g.calls[key]
+		waiting := ok && c.waiters == n
+		g.Unlock()
+		if waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d callers to join the fetch", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalesceConcurrentFetches(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	const callers = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+			if err == nil && pokemon.Name != "pikachu" {
+				err = errors.New("decoded the wrong Pokemon: " + pokemon.Name)
+			}
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, &client.inflight, *client.baseURL.JoinPath("pokemon", "pikachu"), callers)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Coalesced GetPokemon returned an error: %v", err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request to reach the server, found %d", requests.Load())
+	}
+}
+
+func TestCoalesceCancelledCaller(t *testing.T) {
+	var g inflight
+	key := url.URL{Path: "/pokemon/pikachu"}
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-release:
+			return []byte("pikachu"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	impatient := make(chan error)
+	go func() {
+		_, err := g.do(ctx, key, fetch)
+		impatient <- err
+	}()
+	patient := make(chan []byte)
+	go func() {
+		data, _ := g.do(context.Background(), key, fetch)
+		patient <- data
+	}()
+
+	waitForWaiters(t, &g, key, 2)
+	cancel()
+	if err := <-impatient; !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled caller returned %v, expected context.Canceled", err)
+	}
+
+	close(release)
+	if data := <-patient; string(data) != "pikachu" {
+		t.Errorf("Remaining caller didn't get the shared result, found %q", data)
+	}
+}
+
+func TestCoalesceAfterAbandonedFetch(t *testing.T) {
+	var g inflight
+	key := url.URL{Path: "/pokemon/pikachu"}
+	var fetches atomic.Int32
+	tornDown := make(chan struct{})
+	fetch := func(ctx context.Context) ([]byte, error) {
+		if fetches.Add(1) == 1 {
+			// The abandoned fetch takes a while to wind down.
+			<-ctx.Done()
+			<-tornDown
+			return nil, ctx.Err()
+		}
+		return []byte("pikachu"), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := make(chan error)
+	go func() {
+		_, err := g.do(ctx, key, fetch)
+		abandoned <- err
+	}()
+	waitForWaiters(t, &g, key, 1)
+	cancel()
+	if err := <-abandoned; !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled caller returned %v, expected context.Canceled", err)
+	}
+
+	// The first fetch is still winding down, but a new caller mustn't join it.
+	fresh := make(chan error)
+	go func() {
+		data, err := g.do(context.Background(), key, fetch)
+		if err == nil && string(data) != "pikachu" {
+			err = errors.New("wrong data " + string(data))
+		}
+		fresh <- err
+	}()
+	select {
+	case err := <-fresh:
+		if err != nil {
+			t.Errorf("Caller arriving after the fetch was abandoned failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		close(tornDown)
+		t.Fatal("Caller arriving after the fetch was abandoned joined it")
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("Expected a fresh fetch, found %d fetches", n)
+	}
+
+	// The abandoned fetch must not forget the fresh one when it finishes.
+	g.Lock()
+	newer := &call{done: make(chan struct{})}
+	g.calls[key] = newer
+	g.Unlock()
+	close(tornDown)
+	time.Sleep(10 * time.Millisecond)
+	g.Lock()
+	if g.calls[key] != newer {
+		t.Errorf("The abandoned fetch removed a newer call for the same key")
+	}
+	g.Unlock()
+}
@@ -0,0 +1,152 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+/* Errors
+ * Every failure to fetch a resource is reported as one of the types below,
+ * possibly wrapped, so callers can tell them apart with errors.As. The one
+ * exception is cancellation, which is reported as context.Canceled.
+ */
+
+// This is a special error to indicate that a 404 error occured so the caller
+// of a GET request may distinguish between a bad resource name and other
+// more problematic errors.
+type ResourceNotFoundError struct {
+	StatusCode   int
+	ResourceName string
+	URL          string
+}
+
+func (e ResourceNotFoundError) Error() string {
+	return fmt.Sprintf("Resource %v not found: Status code %d", e.ResourceName, e.StatusCode)
+}
+
+// A NetworkError means PokeAPI couldn't be reached, or the connection failed
+// before the whole response was read.
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e NetworkError) Error() string {
+	return fmt.Sprintf("Network error when GET'ing %s: %v", e.URL, e.Err)
+}
+
+func (e NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// A TimeoutError means PokeAPI didn't respond within the Client's timeout or
+// the caller's deadline.
+type TimeoutError struct {
+	URL string
+	Err error
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("Timed out GET'ing %s: %v", e.URL, e.Err)
+}
+
+func (e TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// A RateLimitedError means PokeAPI responded 429 Too Many Requests.
+// RetryAfter is how long the server asked us to wait, or 0 if it didn't say.
+type RateLimitedError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e RateLimitedError) Error() string {
+	return fmt.Sprintf("Rate limited by %s, status: %d", e.URL, e.StatusCode)
+}
+
+// A ServerError means PokeAPI responded with a 5xx status. RetryAfter is how
+// long the server asked us to wait, or 0 if it didn't say.
+type ServerError struct {
+	URL        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e ServerError) Error() string {
+	return fmt.Sprintf("Server error from %s, status: %d", e.URL, e.StatusCode)
+}
+
+// A StatusError means PokeAPI responded with an unexpected status that isn't
+// covered by a more specific error.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("Invalid HTTP response code from %s, status: %d", e.URL, e.StatusCode)
+}
+
+// A DecodeError means the response body wasn't the JSON we expected.
+type DecodeError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("Error unmarshalling response from %s: %v", e.URL, e.Err)
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// An InvalidArgumentError means a request was rejected before it was sent
+// because one of its arguments was out of range.
+type InvalidArgumentError struct {
+	Argument string
+	Value    int
+	Reason   string
+}
+
+func (e InvalidArgumentError) Error() string {
+	return fmt.Sprintf("Invalid %s %d: %s", e.Argument, e.Value, e.Reason)
+}
+
+// Classifies an error returned by http.Client.Do for url. Cancellation by
+// the caller, and requests missing from a replayed cassette, are returned
+// as-is.
+func transportError(ctx context.Context, url string, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("HTTP error when GET'ing %s: %w", url, err)
+	}
+	var unrecorded UnrecordedRequestError
+	if errors.As(err, &unrecorded) {
+		return unrecorded
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return TimeoutError{url, err}
+	}
+	return NetworkError{url, err}
+}
+
+// Classifies a response from url whose status is neither 200, 304 nor 404.
+func statusError(url string, res *http.Response) error {
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return RateLimitedError{url, res.StatusCode, retryAfter(res, time.Now())}
+	case res.StatusCode >= 500:
+		return ServerError{url, res.StatusCode, retryAfter(res, time.Now())}
+	default:
+		return StatusError{url, res.StatusCode}
+	}
+}
@@ -0,0 +1,108 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Serves every request with the given status, Retry-After header and body.
+func statusServer(status int, retryAfter, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestRateLimitedError(t *testing.T) {
+	server := statusServer(http.StatusTooManyRequests, "7", "")
+	defer server.Close()
+
+	client := newTestClient(t, server, WithRetryPolicy(NoRetries))
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	var rateLimited RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Fatalf("Expected a RateLimitedError, got: %v", err)
+	}
+	if rateLimited.RetryAfter != 7*time.Second {
+		t.Errorf("Wrong RetryAfter.\n\tExpected: %v\n\tFound: %v", 7*time.Second, rateLimited.RetryAfter)
+	}
+}
+
+func TestServerError(t *testing.T) {
+	server := statusServer(http.StatusServiceUnavailable, "", "")
+	defer server.Close()
+
+	client := newTestClient(t, server, WithRetryPolicy(NoRetries))
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	var serverErr ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("Expected a ServerError, got: %v", err)
+	}
+	if serverErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Wrong status code.\n\tExpected: %d\n\tFound: %d", http.StatusServiceUnavailable, serverErr.StatusCode)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	server := statusServer(http.StatusBadRequest, "", "")
+	defer server.Close()
+
+	client := newTestClient(t, server, WithRetryPolicy(NoRetries))
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	if !errors.As(err, &StatusError{}) {
+		t.Fatalf("Expected a StatusError, got: %v", err)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	server := statusServer(http.StatusOK, "", "not json")
+	defer server.Close()
+
+	client := newTestClient(t, server, WithRetryPolicy(NoRetries))
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	if !errors.As(err, &DecodeError{}) {
+		t.Fatalf("Expected a DecodeError, got: %v", err)
+	}
+}
+
+func TestNetworkError(t *testing.T) {
+	server := statusServer(http.StatusOK, "", "{}")
+	client := newTestClient(t, server, WithRetryPolicy(NoRetries))
+	server.Close()
+
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	if !errors.As(err, &NetworkError{}) {
+		t.Fatalf("Expected a NetworkError, got: %v", err)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newTestClient(t, server, WithRetryPolicy(NoRetries), WithTimeout(10*time.Millisecond))
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	if !errors.As(err, &TimeoutError{}) {
+		t.Fatalf("Expected a TimeoutError, got: %v", err)
+	}
+}
@@ -6,212 +6,357 @@
 package pokeapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"time"
 
 	"github.com/caleb-fringer/pokedexcli/internal/pokecache"
 )
 
-var BaseUrl *url.URL
-var cache *pokecache.Cache
-
-func init() {
-	var err error
-	BaseUrl, err = url.Parse("https://pokeapi.co/api/v2/")
-	if err != nil {
-		log.Fatal("Error parsing base URL for pokeapi: %w", err)
-	}
-	cache = pokecache.NewCache(5 * time.Second)
-}
-
-/* isCached:
- * Helper function that checks cache for a requested resource, returning the
- * raw data and a boolean indicating if the cache hit or not
+/* fetch
+ * Given the path segments of a resource relative to the Client's base url,
+ * construct the url for the requested endpoint and hand it off to fetchURL.
+ * The last path segment is used as the resource name in a
+ * ResourceNotFoundError.
  */
-func isCached(url url.URL) (data []byte, ok bool) {
-	data, ok = cache.Get(url)
-	if !ok {
-		return nil, false
-	}
-	return
+func fetch[T any](ctx context.Context, c *Client, path ...string) (response T, err error) {
+	url := c.baseURL.JoinPath(path...)
+	return fetchURL[T](ctx, c, url, path[len(path)-1], c.resourceTTL)
 }
 
-/* GetLocationAreas
- * Given a page offset and limit:
- *     -Construct the url for the requested endpoint
- *     -GETs the resource from PokeAPI
+/* fetchURL
+ * Given a fully constructed url, this function will:
+ *     -GET the resource from PokeAPI
  *     -Cache the raw data
- *     -Unmarshal the JSON response into a LocationAreasResponse object
+ *     -Unmarshal the JSON response into an object of type T
  *     -Return the response.
  *
- * If the url is already in  the cache, it will unmarshal that data source
- * instead.
+ * If the url is already in the cache, it will unmarshal that data source
+ * instead. Responses are cached in memory for ttl. If the Client serves stale
+ * responses, an expired cache entry is returned immediately and refreshed in
+ * the background. Either way, an expired entry is refreshed with a conditional
+ * request, so an unchanged resource isn't downloaded again, even once it is
+ * too old to be served stale. A cached 404 is
+ * returned as a ResourceNotFoundError without making a request.
  *
- * Default values for offset, limit should be 0, 20 to request a single page of
- * 20 LocationAreas
- * Returns an error occurs if the url is blank, if the http.GET call fails, if
- * the response's status code is not 200, or if decoding the response fails.
+ * Returns a ResourceNotFoundError carrying `name` if the response's status
+ * code is 404, a DecodeError if decoding the response fails, or the typed
+ * error of the last attempt if every attempt at the GET request fails.
  */
-func GetLocationAreas(offset, limit int) (response LocationAreasResponse, err error) {
-	// Construct query params
-	queryParams := url.Values{}
-	queryParams.Add("offset", strconv.Itoa(offset))
-	queryParams.Add("limit", strconv.Itoa(limit))
-
-	// Construct url w/ populated query params
-	url := BaseUrl.JoinPath("location-area")
-	url.RawQuery = queryParams.Encode()
-	data, ok := isCached(*url)
-
-	// Make HTTP request and cache result on cache miss
-	if !ok {
-		res, err := http.Get(url.String())
-		if err != nil {
-			return response, fmt.Errorf("HTTP error when GET'ing %s: %w", url, err)
-		}
-		defer res.Body.Close()
+func fetchURL[T any](ctx context.Context, c *Client, url *url.URL, name string, ttl time.Duration) (response T, err error) {
+	// Check if the resource is cached
+	entry, ok := c.cache.Lookup(*url)
+	data := entry.Val
 
-		if res.StatusCode != 200 {
-			return response, fmt.Errorf("Invalid HTTP response code from %s, status: %d", url, res.StatusCode)
-		}
+	var stale *pokecache.Entry
+	if ok && entry.Stale && !entry.NotFound {
+		stale = &entry
+	}
 
-		// Cache the result for later use
-		data, err = io.ReadAll(res.Body)
+	switch {
+	case ok && entry.NotFound && !entry.Stale:
+		return response, ResourceNotFoundError{http.StatusNotFound, name, url.String()}
+	case stale != nil && !entry.Expired && c.staleWindow > 0:
+		c.revalidate(url, name, ttl, stale)
+	case !ok || entry.Stale || entry.NotFound:
+		// Make HTTP request and cache result on cache miss
+		data, err = c.fetchRaw(ctx, url, name, ttl, stale)
 		if err != nil {
-			return response, fmt.Errorf("Error reading raw response data to add to cache: %w", err)
+			return response, err
 		}
-		cache.Add(*url, data)
 	}
 
 	// Unmarshall response
 	err = json.Unmarshal(data, &response)
-
 	if err != nil {
-		return response, fmt.Errorf("Error unmarshalling response from %s: %w", url, err)
+		return response, DecodeError{url.String(), http.StatusOK, err}
 	}
 	return response, nil
 }
 
-// This is a special error to indicate that a 404 error occured so the caller
-// of a GET request may distinguish between a bad resource name and other
-// more problematic errors.
-type ResourceNotFoundError struct {
-	StatusCode   int
-	ResourceName string
-}
+/* revalidate
+ * Refreshes the cache entry for url in the background, ignoring any errors.
+ * At most one refresh per url runs at a time.
+ */
+func (c *Client) revalidate(url *url.URL, name string, ttl time.Duration, stale *pokecache.Entry) {
+	if _, running := c.refreshing.LoadOrStore(*url, struct{}{}); running {
+		return
+	}
 
-func (e ResourceNotFoundError) Error() string {
-	return fmt.Sprintf("Resource %v not found: Status code %d", e.ResourceName, e.StatusCode)
+	go func() {
+		defer c.refreshing.Delete(*url)
+		c.fetchRaw(context.Background(), url, name, ttl, stale)
+	}()
 }
 
-/* GetLocationArea
- * Given a specific LocationArea name, this function will:
- *     -Construct the url for the requested endpoint
- *     -GETs the resource from PokeAPI
- *     -Cache the raw data
- *     -Unmarshal the JSON response into a LocationAreaResponse object
- *     -Return the response.
- *
- * If the url is already in  the cache, it will unmarshal that data source
- * instead.
+/* fetchRaw
+ * GETs url and caches the response for ttl, or the Client's notFoundTTL if
+ * the resource doesn't exist. Concurrent calls for the same url share a
+ * single request.
  *
- * Returns an error occurs if the url is blank, if the http.GET call fails, if
- * the response's status code is not 200, or if decoding the response fails.
+ * If a stale cache entry is given, the request is made conditional on its
+ * validators. A 304 response re-adds the stale entry for another ttl and
+ * returns its value.
  */
-func GetLocationArea(name string) (response LocationAreaResponse, err error) {
-	// Construct the url for the requested resource
-	url := BaseUrl.JoinPath("location-area", name)
-
-	// Check if the resource is cached
-	data, ok := isCached(*url)
+func (c *Client) fetchRaw(ctx context.Context, url *url.URL, name string, ttl time.Duration, stale *pokecache.Entry) (data []byte, err error) {
+	var validators pokecache.Validators
+	if stale != nil {
+		validators = stale.Validators
+	}
 
-	// Make HTTP request and cache result on cache miss
-	if !ok {
-		res, err := http.Get(url.String())
+	return c.inflight.do(ctx, *url, func(ctx context.Context) ([]byte, error) {
+		data, fresh, err := c.get(ctx, url, name, validators)
+		if errors.Is(err, errNotModified) && stale != nil {
+			data, err = stale.Val, nil
+			fresh = mergeValidators(fresh, validators)
+		}
+		if errors.As(err, &ResourceNotFoundError{}) && c.notFoundTTL > 0 {
+			c.cache.AddNotFound(*url, c.notFoundTTL)
+		}
 		if err != nil {
-			return response, fmt.Errorf("HTTP error when GET'ing %s: %w", url, err)
+			return nil, err
 		}
-		defer res.Body.Close()
+		c.cache.AddWithValidators(*url, data, ttl, fresh)
+		return data, nil
+	})
+}
+
+/* get
+ * GETs url, retrying transient failures according to the Client's
+ * RetryPolicy. Retries stop early if ctx is cancelled while waiting between
+ * attempts, or if the server asks us to wait longer than the policy's
+ * MaxDelay. Returns the raw response body and validators of the first
+ * successful attempt, or the error of the last one.
+ */
+func (c *Client) get(ctx context.Context, url *url.URL, name string, validators pokecache.Validators) (data []byte, fresh pokecache.Validators, err error) {
+	for attempt := 1; ; attempt++ {
+		data, fresh, err = c.getOnce(ctx, url, name, validators)
 
-		if res.StatusCode == http.StatusNotFound {
-			return response, ResourceNotFoundError{res.StatusCode, name}
+		var retryable retryableError
+		if !errors.As(err, &retryable) {
+			return data, fresh, err
 		}
-		if res.StatusCode != http.StatusOK {
-			return response, fmt.Errorf("Invalid HTTP response code from %s, status: %d", url, res.StatusCode)
+		if attempt >= c.retryPolicy.MaxAttempts {
+			if attempt > 1 {
+				return nil, fresh, fmt.Errorf("Giving up after %d attempts: %w", attempt, retryable.err)
+			}
+			return nil, fresh, retryable.err
 		}
 
-		// Cache the result for later use
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return response, fmt.Errorf("Error reading raw response data to add to cache: %w", err)
+		// Rather than hang for as long as the server asks, give up and let
+		// the caller report when to try again.
+		if retryable.after > c.retryPolicy.MaxDelay {
+			return nil, fresh, retryable.err
+		}
+		delay := max(c.retryPolicy.backoff(attempt), retryable.after)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, fresh, fmt.Errorf("Cancelled while retrying %s: %w", url, err)
 		}
-		cache.Add(*url, data)
 	}
+}
 
-	// Unmarshall response
-	err = json.Unmarshal(data, &response)
+/* getOnce
+ * Waits for the Client's rate limiter, then makes a single GET request for url
+ * and returns the raw response body and its validators. If validators are
+ * given, the request is conditional on them.
+ *
+ * Returns errNotModified on a 304 response, and a ResourceNotFoundError
+ * carrying `name` if the response's status code is 404. Other failures are
+ * reported with the error types in errors.go; transient network errors and
+ * retryable status codes are wrapped in a retryableError.
+ */
+func (c *Client) getOnce(ctx context.Context, url *url.URL, name string, validators pokecache.Validators) (data []byte, fresh pokecache.Validators, err error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fresh, fmt.Errorf("Cancelled while waiting on the rate limit for %s: %w", url, err)
+	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
 	if err != nil {
-		return response, fmt.Errorf("Error unmarshalling response from %s: %w", url, err)
+		return nil, fresh, fmt.Errorf("Error building request for %s: %w", url, err)
 	}
-	return response, nil
+	req.Header.Set("User-Agent", c.userAgent)
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		err = transportError(ctx, url.String(), err)
+		if ctx.Err() != nil || errors.As(err, &UnrecordedRequestError{}) {
+			return nil, fresh, err
+		}
+		return nil, fresh, retryableError{err: err}
+	}
+	defer res.Body.Close()
+
+	fresh = pokecache.Validators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, fresh, errNotModified
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fresh, ResourceNotFoundError{res.StatusCode, name, url.String()}
+	}
+	if res.StatusCode != http.StatusOK {
+		err = statusError(url.String(), res)
+		if c.retryPolicy.retryableStatus(res.StatusCode) {
+			return nil, fresh, retryableError{err, retryAfter(res, time.Now())}
+		}
+		return nil, fresh, err
+	}
+
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fresh, retryableError{err: NetworkError{url.String(), err}}
+	}
+	return data, fresh, nil
 }
 
-/* GetPokemon
- * Given a specific Pokemon name, this function will:
- *     -Construct the url for the requested endpoint
- *     -GETs the resource from PokeAPI
- *     -Cache the raw data
- *     -Unmarshal the JSON response into a Pokemon object
- *     -Return the response.
- *
- * If the url is already in  the cache, it will unmarshal that data source
- * instead.
+// Returned by getOnce when a conditional request finds the cached response
+// is still current.
+var errNotModified = errors.New("Resource not modified")
+
+// A 304 response need not repeat every validator, so keep the old ones where
+// the response has none.
+func mergeValidators(fresh, old pokecache.Validators) pokecache.Validators {
+	if fresh.ETag == "" {
+		fresh.ETag = old.ETag
+	}
+	if fresh.LastModified == "" {
+		fresh.LastModified = old.LastModified
+	}
+	return fresh
+}
+
+/* GetLocationAreas
+ * Given a page offset and limit, fetch a single page of LocationAreas.
+ * Default values for offset, limit should be 0, 20 to request a single page of
+ * 20 LocationAreas.
  *
- * Returns an error occurs if the url is blank, if the http.GET call fails, if
- * the response's status code is not 200, or if decoding the response fails.
+ * Returns an InvalidArgumentError, without making a request, if offset is
+ * negative or limit is less than 1.
  */
-func GetPokemon(name string) (response Pokemon, err error) {
-	// Construct the url for the requested resource
-	url := BaseUrl.JoinPath("pokemon", name)
+func (c *Client) GetLocationAreas(ctx context.Context, offset, limit int) (response LocationAreasResponse, err error) {
+	if offset < 0 {
+		return response, InvalidArgumentError{"offset", offset, "must not be negative"}
+	}
+	if limit < 1 {
+		return response, InvalidArgumentError{"limit", limit, "must be at least 1"}
+	}
 
-	// Check if the resource is cached
-	data, ok := isCached(*url)
+	// Construct query params
+	queryParams := url.Values{}
+	queryParams.Add("offset", strconv.Itoa(offset))
+	queryParams.Add("limit", strconv.Itoa(limit))
 
-	// Make HTTP request and cache result on cache miss
-	if !ok {
-		res, err := http.Get(url.String())
-		if err != nil {
-			return response, fmt.Errorf("HTTP error when GET'ing %s: %w", url, err)
-		}
-		defer res.Body.Close()
+	// Construct url w/ populated query params
+	url := c.baseURL.JoinPath("location-area")
+	url.RawQuery = queryParams.Encode()
+	return fetchURL[LocationAreasResponse](ctx, c, url, "location-area", c.listTTL)
+}
 
-		if res.StatusCode == http.StatusNotFound {
-			return response, ResourceNotFoundError{res.StatusCode, name}
-		}
-		if res.StatusCode != http.StatusOK {
-			return response, fmt.Errorf("Invalid HTTP response code from %s, status: %d", url, res.StatusCode)
-		}
+// GetLocationArea fetches a specific LocationArea by name.
+func (c *Client) GetLocationArea(ctx context.Context, name string) (response LocationAreaResponse, err error) {
+	return fetch[LocationAreaResponse](ctx, c, "location-area", name)
+}
 
-		// Cache the result for later use
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return response, fmt.Errorf("Error reading raw response data to add to cache: %w", err)
-		}
-		cache.Add(*url, data)
-	}
+// GetPokemon fetches a specific Pokemon by name.
+func (c *Client) GetPokemon(ctx context.Context, name string) (response Pokemon, err error) {
+	return fetch[Pokemon](ctx, c, "pokemon", name)
+}
 
-	// Unmarshall response
-	err = json.Unmarshal(data, &response)
+// GetPokemonSpecies fetches a specific PokemonSpecies by name.
+func (c *Client) GetPokemonSpecies(ctx context.Context, name string) (response PokemonSpecies, err error) {
+	return fetch[PokemonSpecies](ctx, c, "pokemon-species", name)
+}
+
+// GetEvolutionChain fetches a specific EvolutionChain by id. Chains have no
+// names; a species' chain id can be found with ResourceID.
+func (c *Client) GetEvolutionChain(ctx context.Context, id int) (response EvolutionChain, err error) {
+	return fetch[EvolutionChain](ctx, c, "evolution-chain", strconv.Itoa(id))
+}
+
+// GetType fetches a specific Type by name.
+func (c *Client) GetType(ctx context.Context, name string) (response Type, err error) {
+	return fetch[Type](ctx, c, "type", name)
+}
+
+// GetMove fetches a specific Move by name.
+func (c *Client) GetMove(ctx context.Context, name string) (response Move, err error) {
+	return fetch[Move](ctx, c, "move", name)
+}
 
+// GetAbility fetches a specific Ability by name.
+func (c *Client) GetAbility(ctx context.Context, name string) (response Ability, err error) {
+	return fetch[Ability](ctx, c, "ability", name)
+}
+
+/* ResourceID
+ * Extracts the id from a resource URL found in a response, such as
+ * https://pokeapi.co/api/v2/evolution-chain/10/. Following the URL itself
+ * would ignore the Client's base URL.
+ */
+func ResourceID(resourceURL string) (int, error) {
+	parsed, err := url.Parse(resourceURL)
 	if err != nil {
-		return response, fmt.Errorf("Error unmarshalling response from %s: %w", url, err)
+		return 0, fmt.Errorf("Error parsing resource url %s: %w", resourceURL, err)
 	}
-	return response, nil
+	id, err := strconv.Atoi(path.Base(parsed.Path))
+	if err != nil {
+		return 0, fmt.Errorf("Resource url %s doesn't end in an id: %w", resourceURL, err)
+	}
+	return id, nil
+}
+
+// GetLocationAreas calls DefaultClient.GetLocationAreas.
+func GetLocationAreas(ctx context.Context, offset, limit int) (response LocationAreasResponse, err error) {
+	return DefaultClient.GetLocationAreas(ctx, offset, limit)
+}
+
+// GetLocationArea calls DefaultClient.GetLocationArea.
+func GetLocationArea(ctx context.Context, name string) (response LocationAreaResponse, err error) {
+	return DefaultClient.GetLocationArea(ctx, name)
+}
+
+// GetPokemon calls DefaultClient.GetPokemon.
+func GetPokemon(ctx context.Context, name string) (response Pokemon, err error) {
+	return DefaultClient.GetPokemon(ctx, name)
+}
+
+// GetPokemonSpecies calls DefaultClient.GetPokemonSpecies.
+func GetPokemonSpecies(ctx context.Context, name string) (response PokemonSpecies, err error) {
+	return DefaultClient.GetPokemonSpecies(ctx, name)
+}
+
+// GetEvolutionChain calls DefaultClient.GetEvolutionChain.
+func GetEvolutionChain(ctx context.Context, id int) (response EvolutionChain, err error) {
+	return DefaultClient.GetEvolutionChain(ctx, id)
+}
+
+// GetType calls DefaultClient.GetType.
+func GetType(ctx context.Context, name string) (response Type, err error) {
+	return DefaultClient.GetType(ctx, name)
+}
+
+// GetMove calls DefaultClient.GetMove.
+func GetMove(ctx context.Context, name string) (response Move, err error) {
+	return DefaultClient.GetMove(ctx, name)
+}
+
+// GetAbility calls DefaultClient.GetAbility.
+func GetAbility(ctx context.Context, name string) (response Ability, err error) {
+	return DefaultClient.GetAbility(ctx, name)
 }
@@ -1,29 +1,194 @@
 package pokeapi
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapitest"
+)
 
 func TestGetLocationAreas(t *testing.T) {
-	response, err := GetLocationAreas(0, 20)
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	response, err := client.GetLocationAreas(context.Background(), 0, 20)
 	if err != nil {
-		t.Fatalf("Querying https://pokeapi.co/api/v2/location-area?offset=0&limit=20 returned an error: %v", err)
+		t.Fatalf("Querying location-area?offset=0&limit=20 returned an error: %v", err)
 	}
 
 	firstArea := response.Results[0].Name
 	if firstArea != "canalave-city-area" {
-		t.Fatalf("Querying the first location-area returned wrong area.\n\tExpected: %s\n\tFound: %s", firstArea, "canalave-city-area")
+		t.Fatalf("Querying the first location-area returned wrong area.\n\tExpected: %s\n\tFound: %s", "canalave-city-area", firstArea)
+	}
+	if len(response.Results) != 20 || response.Next == "" || response.Previous != "" {
+		t.Errorf("Expected a full first page with only a next page, found %d results, next %q, previous %q",
+			len(response.Results), response.Next, response.Previous)
+	}
+}
+
+func TestGetLocationAreasLastPage(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	response, err := client.GetLocationAreas(context.Background(), 20, 20)
+	if err != nil {
+		t.Fatalf("Querying location-area?offset=20&limit=20 returned an error: %v", err)
+	}
+
+	if len(response.Results) != response.Count-20 || response.Next != "" || response.Previous == "" {
+		t.Errorf("Expected the rest of the list with only a previous page, found %d results, next %q, previous %q",
+			len(response.Results), response.Next, response.Previous)
 	}
 }
 
 func TestGetLocationArea(t *testing.T) {
-	response, err := GetLocationArea("pastoria-city-area")
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	response, err := client.GetLocationArea(context.Background(), "pastoria-city-area")
 	if err != nil {
-		t.Fatalf("Querying https://pokeapi.co/api/v2/location-area?offset=0&limit=20 returned an error: %v", err)
+		t.Fatalf("Querying location-area/pastoria-city-area returned an error: %v", err)
 	}
 
 	firstPokemon := response.PokemonEncounters[0].Pokemon.Name
 	if firstPokemon != "tentacool" {
 		t.Fatalf("Querying the pastoria-city-area returned wrong first Pokemon.\n\tExpected: %s\n\tFound: %s",
-			firstPokemon,
-			"tentacool")
+			"tentacool",
+			firstPokemon)
+	}
+}
+
+func TestGetPokemon(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("Querying pokemon/pikachu returned an error: %v", err)
+	}
+
+	if pokemon.BaseExperience != 112 || len(pokemon.Types) != 1 || pokemon.Types[0].Type.Name != "electric" {
+		t.Errorf("Unexpected Pokemon decoded: %+v", pokemon)
+	}
+
+	_, err = client.GetPokemon(context.Background(), "missingno")
+	if !errors.As(err, &ResourceNotFoundError{}) {
+		t.Errorf("Expected a ResourceNotFoundError for an unknown Pokemon, found: %v", err)
+	}
+}
+
+func TestGetPokemonSpecies(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	species, err := client.GetPokemonSpecies(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("Querying pokemon-species/pikachu returned an error: %v", err)
+	}
+
+	if species.CaptureRate != 190 || species.GrowthRate.Name != "medium-fast" || len(species.EggGroups) != 2 {
+		t.Errorf("Unexpected species decoded: %+v", species)
+	}
+	if species.EvolvesFromSpecies.Name != "pichu" || species.EvolutionChain.URL == "" {
+		t.Errorf("Unexpected evolution details decoded: from %q, chain %q",
+			species.EvolvesFromSpecies.Name, species.EvolutionChain.URL)
+	}
+	if species.IsLegendary || species.IsMythical {
+		t.Errorf("pikachu shouldn't be legendary or mythical")
+	}
+}
+
+func TestGetEvolutionChain(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	id, err := ResourceID("https://pokeapi.co/api/v2/evolution-chain/10/")
+	if err != nil || id != 10 {
+		t.Fatalf("ResourceID returned %d, %v", id, err)
+	}
+
+	chain, err := client.GetEvolutionChain(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Querying evolution-chain/10 returned an error: %v", err)
+	}
+
+	pichu := chain.Chain
+	if pichu.Species.Name != "pichu" || !pichu.IsBaby || len(pichu.EvolvesTo) != 1 {
+		t.Fatalf("Unexpected start of chain decoded: %+v", pichu)
+	}
+	pikachu := pichu.EvolvesTo[0]
+	if pikachu.Species.Name != "pikachu" || pikachu.EvolutionDetails[0].MinHappiness != 220 {
+		t.Errorf("Unexpected pikachu link decoded: %+v", pikachu)
+	}
+	raichu := pikachu.EvolvesTo[0]
+	if raichu.EvolutionDetails[0].Trigger.Name != "use-item" || raichu.EvolutionDetails[0].Item.Name != "thunder-stone" {
+		t.Errorf("Unexpected raichu link decoded: %+v", raichu)
+	}
+	if raichu.EvolutionDetails[0].RelativePhysicalStats != nil {
+		t.Errorf("A null relative_physical_stats should decode as nil")
+	}
+
+	if _, err := ResourceID("https://pokeapi.co/api/v2/pokemon/pikachu/"); err == nil {
+		t.Errorf("ResourceID should reject a url without an id")
+	}
+}
+
+func TestGetType(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	flying, err := client.GetType(context.Background(), "flying")
+	if err != nil {
+		t.Fatalf("Querying type/flying returned an error: %v", err)
+	}
+
+	relations := flying.DamageRelations
+	if len(relations.DoubleDamageFrom) != 3 || len(relations.HalfDamageFrom) != 3 {
+		t.Errorf("Unexpected damage relations decoded: %+v", relations)
+	}
+	if len(relations.NoDamageFrom) != 1 || relations.NoDamageFrom[0].Name != "ground" {
+		t.Errorf("Expected flying to take no damage from ground, found: %+v", relations.NoDamageFrom)
+	}
+}
+
+func TestGetMove(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	thunderbolt, err := client.GetMove(context.Background(), "thunderbolt")
+	if err != nil {
+		t.Fatalf("Querying move/thunderbolt returned an error: %v", err)
+	}
+	if thunderbolt.Power == nil || *thunderbolt.Power != 90 || thunderbolt.PP != 15 {
+		t.Errorf("Unexpected move decoded: %+v", thunderbolt)
+	}
+	if thunderbolt.Meta.Ailment.Name != "paralysis" || thunderbolt.Meta.AilmentChance != 10 {
+		t.Errorf("Unexpected move meta decoded: %+v", thunderbolt.Meta)
+	}
+
+	swordsDance, err := client.GetMove(context.Background(), "swords-dance")
+	if err != nil {
+		t.Fatalf("Querying move/swords-dance returned an error: %v", err)
+	}
+	if swordsDance.Power != nil || swordsDance.Accuracy != nil {
+		t.Errorf("A status move's null power and accuracy should decode as nil")
+	}
+}
+
+func TestGetAbility(t *testing.T) {
+	fixtures := pokeapitest.NewServer(t)
+	client := newTestClient(t, fixtures.Server)
+
+	ability, err := client.GetAbility(context.Background(), "lightning-rod")
+	if err != nil {
+		t.Fatalf("Querying ability/lightning-rod returned an error: %v", err)
+	}
+
+	if len(ability.EffectEntries) != 1 || ability.EffectEntries[0].ShortEffect == "" {
+		t.Errorf("Unexpected effect entries decoded: %+v", ability.EffectEntries)
+	}
+	if len(ability.Pokemon) != 5 || ability.Pokemon[0].Pokemon.Name != "pikachu" || !ability.Pokemon[0].IsHidden {
+		t.Errorf("Unexpected Pokemon decoded: %+v", ability.Pokemon)
 	}
 }
@@ -0,0 +1,90 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRateLimit = 10
+	DefaultRateBurst = 10
+)
+
+/* rateLimiter
+ * A token bucket holding up to `burst` tokens, refilled at `rate` tokens per
+ * second. Every request to PokeAPI takes a token, waiting for the bucket to
+ * refill if it is empty. A nil *rateLimiter never blocks.
+ */
+type rateLimiter struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithRateLimit limits the Client to `rate` requests per second, allowing
+// bursts of up to `burst` requests. A rate <= 0 disables rate limiting.
+// Cache hits never count towards the limit.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *Client) {
+		if rate <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(rate, max(burst, 1))
+	}
+}
+
+// Takes a token from the bucket, returning how long the caller must wait
+// before the token may be used.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	if now.After(l.last) {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Returns a token reserved by a caller that gave up waiting for it.
+func (l *rateLimiter) unreserve() {
+	l.Lock()
+	defer l.Unlock()
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// Blocks until a token is available, or returns ctx's error if it is
+// cancelled first.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(time.Now())
+	if delay == 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		l.unreserve()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	limiter := newRateLimiter(2, 2)
+	now := limiter.last
+
+	// The bucket starts full, so the burst goes through immediately.
+	for i := range 2 {
+		if delay := limiter.reserve(now); delay != 0 {
+			t.Errorf("Request %d of the burst was delayed by %v", i+1, delay)
+		}
+	}
+
+	// At 2 tokens/s the next two requests wait 0.5s and 1s respectively.
+	expected := []time.Duration{500 * time.Millisecond, time.Second}
+	for i, delay := range expected {
+		if actual := limiter.reserve(now); actual != delay {
+			t.Errorf("Wrong delay for request %d.\n\tExpected: %v\n\tFound: %v", i+3, delay, actual)
+		}
+	}
+
+	// After one second the debt is repaid, but the bucket is still empty.
+	if delay := limiter.reserve(now.Add(time.Second)); delay != 500*time.Millisecond {
+		t.Errorf("Bucket did not refill as expected, delayed by %v", delay)
+	}
+}
+
+func TestRateLimiterCancellation(t *testing.T) {
+	limiter := newRateLimiter(0.001, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("First Wait returned an error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Wait to respect the context deadline, found: %v", err)
+	}
+}
+
+func TestRateLimitSkipsCacheHits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	defer server.Close()
+
+	// One request every ~17 minutes: any second network request would hang.
+	client := newTestClient(t, server, WithRateLimit(0.001, 1))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for range 3 {
+		if _, err := client.GetPokemon(ctx, "pikachu"); err != nil {
+			t.Fatalf("Cached GetPokemon was rate limited: %v", err)
+		}
+	}
+}
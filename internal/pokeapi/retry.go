@@ -0,0 +1,116 @@
+package pokeapi
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+/* RetryPolicy
+ * Controls how a Client retries requests that fail with a transient network
+ * error or a retryable status code. Delays grow exponentially from BaseDelay,
+ * are capped at MaxDelay, and a random fraction (Jitter, in [0,1]) of each
+ * delay is shaved off so that concurrent clients don't retry in lockstep.
+ *
+ * Responses with status 429 or 503 that carry a Retry-After header wait at
+ * least as long as the server asked for, unless that is longer than MaxDelay,
+ * in which case the request isn't retried at all.
+ */
+type RetryPolicy struct {
+	MaxAttempts       int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	Jitter            float64
+	RetryableStatuses []int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.5,
+	RetryableStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// NoRetries is a RetryPolicy that makes exactly one attempt per request.
+var NoRetries = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy replaces the Client's DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	return slices.Contains(p.RetryableStatuses, statusCode)
+}
+
+// The delay to wait after the given (1-indexed) failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+
+	return delay - time.Duration(p.Jitter*rand.Float64()*float64(delay))
+}
+
+// Wraps the error of an attempt that is worth retrying. `after` is the
+// minimum delay requested by the server, if any.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+/* retryAfter
+ * Parses the Retry-After header of a 429 or 503 response, which may be
+ * either a number of seconds or an HTTP date. Returns 0 if the header is
+ * absent, unparseable, or the response has any other status.
+ */
+func retryAfter(res *http.Response, now time.Time) time.Duration {
+	if res.StatusCode != http.StatusTooManyRequests &&
+		res.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	header := res.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// Sleeps for d, returning early with ctx's error if it is cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
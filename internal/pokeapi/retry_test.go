@@ -0,0 +1,135 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var fastRetries = RetryPolicy{
+	MaxAttempts:       3,
+	BaseDelay:         time.Millisecond,
+	MaxDelay:          5 * time.Millisecond,
+	RetryableStatuses: DefaultRetryPolicy.RetryableStatuses,
+}
+
+// Responds with each of statuses in turn, then 200 for every later request.
+func flakyServer(t *testing.T, requests *int, statuses ...int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *requests <= len(statuses) {
+			w.WriteHeader(statuses[*requests-1])
+			return
+		}
+		w.Write([]byte(`{"name": "pikachu"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	requests := 0
+	server := flakyServer(t, &requests, http.StatusBadGateway, http.StatusServiceUnavailable)
+
+	client := newTestClient(t, server, WithRetryPolicy(fastRetries))
+	pokemon, err := client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon returned an error despite a successful retry: %v", err)
+	}
+	if pokemon.Name != "pikachu" {
+		t.Errorf("Unexpected Pokemon decoded: %s", pokemon.Name)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests to reach the server, found %d", requests)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	requests := 0
+	server := flakyServer(t, &requests, 500, 500, 500, 500)
+
+	client := newTestClient(t, server, WithRetryPolicy(fastRetries))
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); err == nil {
+		t.Fatal("Expected an error after exhausting every attempt")
+	}
+	if requests != fastRetries.MaxAttempts {
+		t.Errorf("Expected %d requests to reach the server, found %d", fastRetries.MaxAttempts, requests)
+	}
+}
+
+func TestRetrySkipsPermanentFailures(t *testing.T) {
+	requests := 0
+	server := flakyServer(t, &requests, http.StatusBadRequest)
+
+	client := newTestClient(t, server, WithRetryPolicy(fastRetries))
+	if _, err := client.GetPokemon(context.Background(), "pikachu"); err == nil {
+		t.Fatal("Expected an error for a 400 response")
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request for a non-retryable status, found %d", requests)
+	}
+}
+
+func TestRetryAfterBeyondMaxDelay(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, WithRetryPolicy(fastRetries))
+	start := time.Now()
+	_, err := client.GetPokemon(context.Background(), "pikachu")
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Waited %v on a Retry-After longer than MaxDelay", elapsed)
+	}
+	var rateLimited RateLimitedError
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != time.Hour {
+		t.Fatalf("Expected a RateLimitedError asking for an hour, found: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single request when Retry-After exceeds MaxDelay, found %d", requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		status   int
+		header   string
+		expected time.Duration
+	}{
+		{http.StatusTooManyRequests, "3", 3 * time.Second},
+		{http.StatusServiceUnavailable, now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{http.StatusServiceUnavailable, "soon", 0},
+		{http.StatusInternalServerError, "3", 0},
+	}
+
+	for _, testCase := range testCases {
+		res := &http.Response{StatusCode: testCase.status, Header: http.Header{}}
+		res.Header.Set("Retry-After", testCase.header)
+
+		if actual := retryAfter(res, now); actual != testCase.expected {
+			t.Errorf("Wrong delay for status %d, Retry-After %q.\n\tExpected: %v\n\tFound: %v",
+				testCase.status, testCase.header, testCase.expected, actual)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+
+	for i, delay := range expected {
+		if actual := policy.backoff(i + 1); actual != delay {
+			t.Errorf("Wrong backoff for attempt %d.\n\tExpected: %v\n\tFound: %v", i+1, delay, actual)
+		}
+	}
+}
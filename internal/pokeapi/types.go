@@ -327,3 +327,349 @@ type Pokemon struct {
 	} `json:"types"`
 	Weight int `json:"weight"`
 }
+
+// Response from https://pokeapi.co/api/v2/pokemon-species/{id or name}/
+type PokemonSpecies struct {
+	BaseHappiness int `json:"base_happiness"`
+	CaptureRate   int `json:"capture_rate"`
+	Color         struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"color"`
+	EggGroups []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"egg_groups"`
+	EvolutionChain struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	EvolvesFromSpecies struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"evolves_from_species"`
+	FlavorTextEntries []struct {
+		FlavorText string `json:"flavor_text"`
+		Language   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Version struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"version"`
+	} `json:"flavor_text_entries"`
+	FormDescriptions []any `json:"form_descriptions"`
+	FormsSwitchable  bool  `json:"forms_switchable"`
+	GenderRate       int   `json:"gender_rate"`
+	Genera           []struct {
+		Genus    string `json:"genus"`
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+	} `json:"genera"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	GrowthRate struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"growth_rate"`
+	Habitat struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"habitat"`
+	HasGenderDifferences bool   `json:"has_gender_differences"`
+	HatchCounter         int    `json:"hatch_counter"`
+	ID                   int    `json:"id"`
+	IsBaby               bool   `json:"is_baby"`
+	IsLegendary          bool   `json:"is_legendary"`
+	IsMythical           bool   `json:"is_mythical"`
+	Name                 string `json:"name"`
+	Names                []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+	Order          int `json:"order"`
+	PokedexNumbers []struct {
+		EntryNumber int `json:"entry_number"`
+		Pokedex     struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokedex"`
+	} `json:"pokedex_numbers"`
+	Shape struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"shape"`
+	Varieties []struct {
+		IsDefault bool `json:"is_default"`
+		Pokemon   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+// Response from https://pokeapi.co/api/v2/evolution-chain/{id}/
+type EvolutionChain struct {
+	BabyTriggerItem struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"baby_trigger_item"`
+	Chain ChainLink `json:"chain"`
+	ID    int       `json:"id"`
+}
+
+// A species in an EvolutionChain, the ways it evolves from the species
+// before it, and the species it evolves into.
+type ChainLink struct {
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+	IsBaby           bool              `json:"is_baby"`
+	Species          struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"species"`
+}
+
+// The conditions of one way to evolve. Conditions that don't apply are left
+// as zero values, except RelativePhysicalStats, where 0 is meaningful.
+type EvolutionDetail struct {
+	Gender   int `json:"gender"`
+	HeldItem struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"held_item"`
+	Item struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"item"`
+	KnownMove struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"known_move"`
+	KnownMoveType struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"known_move_type"`
+	Location struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"location"`
+	MinAffection       int  `json:"min_affection"`
+	MinBeauty          int  `json:"min_beauty"`
+	MinHappiness       int  `json:"min_happiness"`
+	MinLevel           int  `json:"min_level"`
+	NeedsOverworldRain bool `json:"needs_overworld_rain"`
+	PartySpecies       struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"party_species"`
+	PartyType struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"party_type"`
+	RelativePhysicalStats *int   `json:"relative_physical_stats"`
+	TimeOfDay             string `json:"time_of_day"`
+	TradeSpecies          struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"trade_species"`
+	Trigger struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"trigger"`
+	TurnUpsideDown bool `json:"turn_upside_down"`
+}
+
+// Response from https://pokeapi.co/api/v2/type/{id or name}/
+type Type struct {
+	DamageRelations struct {
+		DoubleDamageFrom []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"double_damage_from"`
+		DoubleDamageTo []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"double_damage_to"`
+		HalfDamageFrom []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"half_damage_from"`
+		HalfDamageTo []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"half_damage_to"`
+		NoDamageFrom []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"no_damage_from"`
+		NoDamageTo []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"no_damage_to"`
+	} `json:"damage_relations"`
+	GameIndices []struct {
+		GameIndex  int `json:"game_index"`
+		Generation struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"generation"`
+	} `json:"game_indices"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	ID              int `json:"id"`
+	MoveDamageClass struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"move_damage_class"`
+	Moves []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"moves"`
+	Name  string `json:"name"`
+	Names []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+	PastDamageRelations []any `json:"past_damage_relations"`
+	Pokemon             []struct {
+		Pokemon struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+		Slot int `json:"slot"`
+	} `json:"pokemon"`
+}
+
+// Response from https://pokeapi.co/api/v2/move/{id or name}/
+// Power, Accuracy and EffectChance are nil for moves that don't use them,
+// such as status moves.
+type Move struct {
+	Accuracy    *int `json:"accuracy"`
+	DamageClass struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"damage_class"`
+	EffectChance  *int `json:"effect_chance"`
+	EffectEntries []struct {
+		Effect   string `json:"effect"`
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		ShortEffect string `json:"short_effect"`
+	} `json:"effect_entries"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	ID               int `json:"id"`
+	LearnedByPokemon []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"learned_by_pokemon"`
+	Meta struct {
+		Ailment struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"ailment"`
+		AilmentChance int `json:"ailment_chance"`
+		Category      struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"category"`
+		CritRate     int  `json:"crit_rate"`
+		Drain        int  `json:"drain"`
+		FlinchChance int  `json:"flinch_chance"`
+		Healing      int  `json:"healing"`
+		MaxHits      *int `json:"max_hits"`
+		MaxTurns     *int `json:"max_turns"`
+		MinHits      *int `json:"min_hits"`
+		MinTurns     *int `json:"min_turns"`
+		StatChance   int  `json:"stat_chance"`
+	} `json:"meta"`
+	Name  string `json:"name"`
+	Names []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+	Power       *int `json:"power"`
+	PP          int  `json:"pp"`
+	Priority    int  `json:"priority"`
+	StatChanges []struct {
+		Change int `json:"change"`
+		Stat   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"stat"`
+	} `json:"stat_changes"`
+	Target struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"target"`
+	Type struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"type"`
+}
+
+// Response from https://pokeapi.co/api/v2/ability/{id or name}/
+type Ability struct {
+	EffectChanges []any `json:"effect_changes"`
+	EffectEntries []struct {
+		Effect   string `json:"effect"`
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		ShortEffect string `json:"short_effect"`
+	} `json:"effect_entries"`
+	FlavorTextEntries []struct {
+		FlavorText string `json:"flavor_text"`
+		Language   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		VersionGroup struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"version_group"`
+	} `json:"flavor_text_entries"`
+	Generation struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	ID           int    `json:"id"`
+	IsMainSeries bool   `json:"is_main_series"`
+	Name         string `json:"name"`
+	Names        []struct {
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+		Name string `json:"name"`
+	} `json:"names"`
+	Pokemon []struct {
+		IsHidden bool `json:"is_hidden"`
+		Pokemon  struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"pokemon"`
+		Slot int `json:"slot"`
+	} `json:"pokemon"`
+}
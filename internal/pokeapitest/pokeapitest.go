@@ -0,0 +1,167 @@
+/* Package pokeapitest serves recorded PokeAPI responses from an httptest
+ * server, so tests can exercise the pokeapi client and the REPL without
+ * reaching pokeapi.co.
+ *
+ * Fixtures live in testdata, laid out the way PokeAPI lays out its URLs:
+ *     -testdata/{resource}/{name}.json is served at /{resource}/{name}
+ *     -testdata/{resource}.json holds the full list of a resource, and is
+ *      served a page at a time at /{resource}?offset=..&limit=..
+ *
+ * Anything without a fixture is a 404, just like an unknown name on PokeAPI.
+ */
+package pokeapitest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+// Default page size of a list, as on PokeAPI.
+const defaultLimit = 20
+
+// A Server is an httptest.Server serving the fixtures in testdata. It counts
+// the requests made for each path.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// NewServer starts a fixture server, which is closed when the test finishes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{requests: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// BaseURL returns the server's URL, for use with pokeapi.WithBaseURL.
+func (s *Server) BaseURL() *url.URL {
+	baseURL, err := url.Parse(s.URL)
+	if err != nil {
+		panic(err)
+	}
+	return baseURL
+}
+
+// Requests returns how many requests have been made for urlPath, e.g.
+// "/pokemon/pikachu", regardless of their query.
+func (s *Server) Requests(urlPath string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[urlPath]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	urlPath := "/" + strings.Trim(r.URL.Path, "/")
+	s.mu.Lock()
+	s.requests[urlPath]++
+	s.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(urlPath, "/"), "/")
+	switch len(parts) {
+	case 1:
+		s.serveList(w, r, parts[0])
+	case 2:
+		s.serveFile(w, path.Join("testdata", parts[0], parts[1]+".json"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, name string) {
+	data, err := fs.ReadFile(testdata, name)
+	if err != nil {
+		http.Error(w, `{"detail": "Not found."}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// The parts of a list response that the server rewrites for each page.
+type namedResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type listResponse struct {
+	Count    int             `json:"count"`
+	Next     *string         `json:"next"`
+	Previous *string         `json:"previous"`
+	Results  []namedResource `json:"results"`
+}
+
+/* serveList
+ * Serves one page of testdata/{resource}.json. Like PokeAPI, the offset and
+ * limit default to 0 and 20, and next and previous link to the neighbouring
+ * pages on this server, or are null at either end of the list.
+ */
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, resource string) {
+	data, err := fs.ReadFile(testdata, path.Join("testdata", resource+".json"))
+	if err != nil {
+		http.Error(w, `{"detail": "Not found."}`, http.StatusNotFound)
+		return
+	}
+	var list listResponse
+	if err := json.Unmarshal(data, &list); err != nil {
+		http.Error(w, fmt.Sprintf("Bad fixture %s.json: %v", resource, err), http.StatusInternalServerError)
+		return
+	}
+
+	offset, err := queryInt(r.URL.Query(), "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Bad offset", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r.URL.Query(), "limit", defaultLimit)
+	if err != nil || limit < 1 {
+		http.Error(w, "Bad limit", http.StatusBadRequest)
+		return
+	}
+
+	start, end := min(offset, len(list.Results)), min(offset+limit, len(list.Results))
+	page := listResponse{Count: len(list.Results), Results: list.Results[start:end]}
+	if end < len(list.Results) {
+		next := s.pageURL(resource, end, limit)
+		page.Next = &next
+	}
+	if offset > 0 {
+		previous := s.pageURL(resource, max(offset-limit, 0), limit)
+		page.Previous = &previous
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *Server) pageURL(resource string, offset, limit int) string {
+	return fmt.Sprintf("%s/%s?offset=%d&limit=%d", s.URL, resource, offset, limit)
+}
+
+func queryInt(query url.Values, key string, fallback int) (int, error) {
+	if !query.Has(key) {
+		return fallback, nil
+	}
+	return strconv.Atoi(query.Get(key))
+}
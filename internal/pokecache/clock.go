@@ -0,0 +1,42 @@
+package pokecache
+
+import "time"
+
+// A Clock tells the Cache the time and drives its reap loop. Tests can
+// substitute a fake Clock to control expiry without sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// A Ticker delivers ticks on C until it is stopped, like a *time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock replaces the real clock used by the Cache.
+func WithClock(clock Clock) Option {
+	return func(cache *Cache) {
+		cache.clock = clock
+	}
+}
+
+// realClock is a Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,69 @@
+package pokecache
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when Advance is called.
+type fakeClock struct {
+	sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	clock    *fakeClock
+	c        chan time.Time
+	interval time.Duration
+	next     time.Time
+	stopped  bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.Lock()
+	defer c.Unlock()
+	ticker := &fakeTicker{clock: c, c: make(chan time.Time, 1), interval: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance moves the clock forward by d, firing any tickers that come due.
+// Like a *time.Ticker, ticks are dropped if the previous one wasn't received.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	for _, ticker := range c.tickers {
+		if ticker.stopped || ticker.next.After(c.now) {
+			continue
+		}
+		select {
+		case ticker.c <- c.now:
+		default:
+		}
+		for !ticker.next.After(c.now) {
+			ticker.next = ticker.next.Add(ticker.interval)
+		}
+	}
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.Lock()
+	defer t.clock.Unlock()
+	t.stopped = true
+}
@@ -0,0 +1,125 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const DefaultDiskTTL = 30 * 24 * time.Hour
+
+/* diskTier
+ * A second cache tier that stores one JSON file per entry in dir, named
+ * after the SHA-256 of the entry's URL. Entries outlive the process and
+ * expire after their own ttl, independent of the in-memory interval.
+ *
+ * The disk tier is best effort: failing to read or write a file is treated
+ * as a cache miss rather than an error.
+ */
+type diskTier struct {
+	dir string
+	ttl time.Duration
+}
+
+// The on-disk representation of a cache entry.
+type diskEntry struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	Val       []byte    `json:"val"`
+	Validators
+}
+
+// DefaultDiskDir returns $XDG_CACHE_HOME/pokedexcli, or the platform's
+// equivalent user cache directory.
+func DefaultDiskDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pokedexcli"), nil
+}
+
+// WithDiskTier backs the Cache with entries stored in dir, which is created
+// if needed. Entries on disk expire after ttl.
+func WithDiskTier(dir string, ttl time.Duration) Option {
+	return func(cache *Cache) {
+		cache.disk = &diskTier{dir, ttl}
+	}
+}
+
+func (d *diskTier) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Reads the entry for key, removing it from disk if it expired more than
+// `window` ago and has no validators to revalidate it with.
+func (d *diskTier) get(key string, now time.Time, window time.Duration) (entry diskEntry, ok bool) {
+	path := d.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, false
+	}
+
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != key {
+		return entry, false
+	}
+	if now.Sub(entry.CreatedAt) >= d.ttl+window && entry.Validators == (Validators{}) {
+		os.Remove(path)
+		return entry, false
+	}
+	return entry, true
+}
+
+// Writes the entry to a temporary file and renames it into place, so that
+// readers never see a partially written entry.
+func (d *diskTier) add(entry diskEntry) {
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	os.Rename(tmp.Name(), d.path(entry.URL))
+}
+
+// Removes every entry from disk.
+func (d *diskTier) clear() {
+	paths, _ := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
+
+// Reads every unexpired entry on disk, skipping any that can't be read.
+func (d *diskTier) all(now time.Time) (entries []diskEntry) {
+	paths, _ := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry diskEntry
+		if err := json.Unmarshal(data, &entry); err != nil || now.Sub(entry.CreatedAt) >= d.ttl {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
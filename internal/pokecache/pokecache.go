@@ -1,60 +1,363 @@
 package pokecache
 
 import (
+	"container/list"
 	"net/url"
 	"sync"
 	"time"
 )
 
+/* Cache
+ * An in-memory cache of raw responses keyed by URL. Entries expire after the
+ * interval passed to NewCache, or after their own TTL if added with
+ * AddWithTTL. If the Cache is bounded by WithMaxEntries or WithMaxBytes, the
+ * least recently used entries are evicted to stay within those bounds.
+ *
+ * With WithStaleWindow, expired entries are kept for a while longer so that
+ * Lookup can still return them, marked as stale. Past the stale window,
+ * entries with validators are still kept, marked as expired, so that they can
+ * be revalidated with a conditional request. They are dropped from memory
+ * once they have been expired for as long as the disk tier keeps entries, or
+ * for DefaultDiskTTL if there is no disk tier.
+ */
 type Cache struct {
 	sync.Mutex
-	entries  map[url.URL]cacheEntry
+	entries  map[url.URL]*list.Element
+	lru      *list.List // Values are *cacheEntry, most recently used first
 	interval time.Duration
+	disk     *diskTier
+	clock    Clock
+
+	staleWindow time.Duration
+
+	maxEntries int
+	maxBytes   int
+	bytes      int
+
+	hits        int
+	misses      int
+	evictions   int
+	expirations int
+
+	closeOnce sync.Once
+	done      chan struct{} // Closed to stop the reap loop
+	stopped   chan struct{} // Closed once the reap loop has returned
 }
 
 type cacheEntry struct {
-	createdAt time.Time
-	val       []byte
+	key        url.URL
+	createdAt  time.Time
+	expiresAt  time.Time
+	val        []byte
+	notFound   bool
+	validators Validators
+}
+
+// An Entry is a cached value as returned by Lookup.
+type Entry struct {
+	Val       []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Stale     bool // The entry has expired
+	Expired   bool // The entry is past the stale window; only revalidate it
+	NotFound  bool // The entry records that the resource doesn't exist
+	Validators
+}
+
+// Validators are the response headers used to revalidate a cached response
+// with a conditional request.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// An Option configures a Cache built by NewCache.
+type Option func(*Cache)
+
+// WithMaxEntries bounds the number of entries held in memory. A bound of 0
+// means unlimited.
+func WithMaxEntries(maxEntries int) Option {
+	return func(cache *Cache) {
+		cache.maxEntries = maxEntries
+	}
+}
+
+// WithMaxBytes bounds the total size of the values held in memory. A bound of
+// 0 means unlimited.
+func WithMaxBytes(maxBytes int) Option {
+	return func(cache *Cache) {
+		cache.maxBytes = maxBytes
+	}
 }
 
-func NewCache(interval time.Duration) (cache *Cache) {
+// WithStaleWindow keeps entries for `window` after they expire, so Lookup can
+// serve them stale while the caller refreshes them.
+func WithStaleWindow(window time.Duration) Option {
+	return func(cache *Cache) {
+		cache.staleWindow = window
+	}
+}
+
+func NewCache(interval time.Duration, opts ...Option) (cache *Cache) {
 	cache = &Cache{
-		entries:  make(map[url.URL]cacheEntry),
+		entries:  make(map[url.URL]*list.Element),
+		lru:      list.New(),
 		interval: interval,
+		clock:    realClock{},
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(cache)
 	}
-	go cache.reapLoop()
+	go cache.reapLoop(cache.clock.NewTicker(interval))
 	return cache
 }
 
+// Add stores val under key in memory, and on disk if the Cache has a disk
+// tier. The entry expires after the Cache's interval.
 func (cache *Cache) Add(key url.URL, val []byte) {
-	entry := cacheEntry{time.Now(), val}
+	cache.AddWithTTL(key, val, cache.interval)
+}
+
+// AddWithTTL is like Add, but the in-memory entry expires after ttl instead
+// of the Cache's interval. The disk tier always uses its own TTL.
+func (cache *Cache) AddWithTTL(key url.URL, val []byte, ttl time.Duration) {
+	cache.AddWithValidators(key, val, ttl, Validators{})
+}
+
+// AddWithValidators is like AddWithTTL, but also stores the validators of the
+// response so that it can be revalidated once it expires. Re-adding an entry
+// with the same value is how a revalidated entry is extended.
+func (cache *Cache) AddWithValidators(key url.URL, val []byte, ttl time.Duration, validators Validators) {
+	now := cache.clock.Now()
 	cache.Lock()
-	defer cache.Unlock()
-	cache.entries[key] = entry
+	cache.set(&cacheEntry{
+		key:        key,
+		createdAt:  now,
+		expiresAt:  now.Add(ttl),
+		val:        val,
+		validators: validators,
+	})
+	cache.Unlock()
+
+	if cache.disk != nil {
+		cache.disk.add(diskEntry{key.String(), now, val, validators})
+	}
 }
 
-func (cache *Cache) Get(key url.URL) (entryData []byte, ok bool) {
+// AddNotFound records in memory that the resource at key doesn't exist, for
+// ttl. Lookup returns such entries with NotFound set; Get treats them as
+// misses. They are never written to the disk tier.
+func (cache *Cache) AddNotFound(key url.URL, ttl time.Duration) {
+	now := cache.clock.Now()
 	cache.Lock()
 	defer cache.Unlock()
-	entry, ok := cache.entries[key]
+	cache.set(&cacheEntry{key: key, createdAt: now, expiresAt: now.Add(ttl), notFound: true})
+}
+
+// Get looks up a fresh entry for key, as Lookup does, and returns its value.
+// Stale and NotFound entries are treated as misses.
+func (cache *Cache) Get(key url.URL) (entryData []byte, ok bool) {
+	entry, ok := cache.lookup(key)
+	ok = ok && !entry.Stale && !entry.NotFound
+	cache.record(ok)
 	if !ok {
 		return nil, false
 	}
-	return entry.val, true
+	return entry.Val, true
 }
 
-func (cache *Cache) reapLoop() {
-	ticker := time.NewTicker(cache.interval)
+/* Lookup
+ * Looks up key in memory, falling through to the disk tier if there is one
+ * and the memory entry is missing or expired. Disk hits are copied back into
+ * memory, expiring after the Cache's interval or when they would expire on
+ * disk, whichever is sooner.
+ *
+ * Expired entries are treated as misses even if the reap loop has not
+ * removed them yet, unless they are within the stale window, in which case
+ * they are returned with Stale set. Entries past the stale window that have
+ * validators are returned with Expired set too, and count as misses; their
+ * value may only be used once a conditional request confirms it's unchanged.
+ */
+func (cache *Cache) Lookup(key url.URL) (entry Entry, ok bool) {
+	entry, ok = cache.lookup(key)
+	cache.record(ok && !entry.Expired)
+	return entry, ok
+}
+
+func (cache *Cache) lookup(key url.URL) (entry Entry, ok bool) {
+	now := cache.clock.Now()
+
+	cache.Lock()
+	var cached *cacheEntry
+	if elem, found := cache.entries[key]; found {
+		cached = elem.Value.(*cacheEntry)
+		if cache.reapable(cached, now) {
+			cache.remove(elem)
+			cache.expirations++
+			cached = nil
+		} else {
+			cache.lru.MoveToFront(elem)
+		}
+	}
+	cache.Unlock()
+	if cached != nil && now.Before(cached.expiresAt) {
+		return cache.toEntry(cached, now), true
+	}
+
+	// The memory copy is missing or has expired, but the disk copy may
+	// outlive it, so check the disk before serving a stale entry.
+	if promoted := cache.promote(key, cached, now); promoted != nil {
+		return cache.toEntry(promoted, now), true
+	}
+	if cached != nil {
+		return cache.toEntry(cached, now), true
+	}
+	return entry, false
+}
+
+/* promote
+ * Copies the disk tier's entry for key into memory, expiring after the
+ * Cache's interval or when it would expire on disk, whichever is sooner.
+ * Returns nil, leaving memory untouched, if there is no disk tier, no usable
+ * entry on disk, or the disk entry expires no later than the expired memory
+ * entry `cached`.
+ */
+func (cache *Cache) promote(key url.URL, cached *cacheEntry, now time.Time) *cacheEntry {
+	if cache.disk == nil {
+		return nil
+	}
+	stored, ok := cache.disk.get(key.String(), now, cache.staleWindow)
+	if !ok {
+		return nil
+	}
+
+	expiresAt := stored.CreatedAt.Add(cache.disk.ttl)
+	if cached != nil && !expiresAt.After(cached.expiresAt) {
+		return nil
+	}
+	if fresh := now.Add(cache.interval); fresh.Before(expiresAt) {
+		expiresAt = fresh
+	}
+	promoted := &cacheEntry{
+		key:        key,
+		createdAt:  now,
+		expiresAt:  expiresAt,
+		val:        stored.Val,
+		validators: stored.Validators,
+	}
+
+	cache.Lock()
+	cache.set(promoted)
+	cache.Unlock()
+	return promoted
+}
+
+func (cache *Cache) toEntry(entry *cacheEntry, now time.Time) Entry {
+	return Entry{
+		Val:        entry.val,
+		CreatedAt:  entry.createdAt,
+		ExpiresAt:  entry.expiresAt,
+		Stale:      !now.Before(entry.expiresAt),
+		Expired:    !now.Before(entry.expiresAt.Add(cache.staleWindow)),
+		NotFound:   entry.notFound,
+		Validators: entry.validators,
+	}
+}
+
+// Len returns the number of entries held in memory.
+func (cache *Cache) Len() int {
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.lru.Len()
+}
+
+// Inserts or replaces an entry as the most recently used, then evicts the
+// least recently used entries until the Cache is within its bounds. The
+// caller must hold the lock.
+func (cache *Cache) set(entry *cacheEntry) {
+	if elem, ok := cache.entries[entry.key]; ok {
+		cache.remove(elem)
+	}
+	cache.entries[entry.key] = cache.lru.PushFront(entry)
+	cache.bytes += len(entry.val)
+
+	for cache.overBounds() {
+		cache.remove(cache.lru.Back())
+		cache.evictions++
+	}
+}
+
+func (cache *Cache) overBounds() bool {
+	if cache.maxEntries > 0 && cache.lru.Len() > cache.maxEntries {
+		return true
+	}
+	return cache.maxBytes > 0 && cache.bytes > cache.maxBytes
+}
+
+// The caller must hold the lock.
+func (cache *Cache) remove(elem *list.Element) {
+	entry := cache.lru.Remove(elem).(*cacheEntry)
+	delete(cache.entries, entry.key)
+	cache.bytes -= len(entry.val)
+}
+
+/* Close
+ * Stops the goroutine that reaps expired entries and waits for it to exit.
+ * The Cache may still be used afterwards, but entries will only leave it
+ * through eviction. Calling Close more than once is a no-op.
+ */
+func (cache *Cache) Close() {
+	cache.closeOnce.Do(func() {
+		close(cache.done)
+	})
+	<-cache.stopped
+}
+
+// Whether the entry is past both its expiry and the stale window, and, if it
+// has validators to revalidate it with, past the revalidation window too.
+func (cache *Cache) reapable(entry *cacheEntry, now time.Time) bool {
+	keep := cache.staleWindow
+	if entry.validators != (Validators{}) {
+		keep = max(keep, cache.revalidationWindow())
+	}
+	return !now.Before(entry.expiresAt.Add(keep))
+}
+
+// How long expired entries with validators are kept in memory: as long as
+// the disk tier keeps entries, or DefaultDiskTTL without a disk tier.
+func (cache *Cache) revalidationWindow() time.Duration {
+	if cache.disk != nil {
+		return cache.disk.ttl
+	}
+	return DefaultDiskTTL
+}
+
+func (cache *Cache) reapLoop(ticker Ticker) {
 	defer ticker.Stop()
+	defer close(cache.stopped)
 
 	for {
-		<-ticker.C
-		cache.Lock()
-		for key, val := range cache.entries {
-			if time.Since(val.createdAt) >= cache.interval {
-				delete(cache.entries, key)
-			}
+		select {
+		case <-cache.done:
+			return
+		case <-ticker.C():
+		}
+		cache.reap()
+	}
+}
+
+// Removes every entry that reapable reports from memory.
+func (cache *Cache) reap() {
+	now := cache.clock.Now()
+
+	cache.Lock()
+	defer cache.Unlock()
+	for _, elem := range cache.entries {
+		if cache.reapable(elem.Value.(*cacheEntry), now) {
+			cache.remove(elem)
+			cache.expirations++
 		}
-		cache.Unlock()
 	}
 }
@@ -1,9 +1,12 @@
 package pokecache
 
 import (
+	"bytes"
 	"fmt"
 	"net/url"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -21,26 +24,402 @@ func init() {
 
 func TestAdd(t *testing.T) {
 	cache := NewCache(5 * time.Second)
+	defer cache.Close()
 	cache.Add(*testUrl, []byte("This is test data"))
 }
 
 func TestGet(t *testing.T) {
-	cache := NewCache(5 * time.Second)
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock))
+	defer cache.Close()
 	cache.Add(*testUrl, []byte{})
-	time.Sleep(time.Second)
+	clock.Advance(time.Second)
 	if _, ok := cache.Get(*testUrl); !ok {
 		t.Fatal("Reap loop removed the entry before time was up.")
 	}
+	clock.Advance(4 * time.Second)
+	if _, ok := cache.Get(*testUrl); ok {
+		t.Fatal("Get returned an expired entry.")
+	}
 }
 
 func TestReapLoop(t *testing.T) {
-	cache := NewCache(5 * time.Second)
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock))
+	defer cache.Close()
 	cache.Add(*testUrl, []byte{})
-	if _, ok := cache.Get(*testUrl); !ok {
+	clock.Advance(4 * time.Second)
+	if cache.Len() != 1 {
 		t.Fatal("Reap loop removed the entry before time was up.")
 	}
-	time.Sleep(5 * time.Second)
+
+	// The tick is delivered immediately, but reaped on the loop's goroutine.
+	clock.Advance(time.Second)
+	deadline := time.Now().Add(time.Second)
+	for cache.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Reap loop failed to remove *test entry")
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestDiskTier(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := NewCache(5*time.Second, WithDiskTier(dir, time.Hour))
+	defer cache.Close()
+	cache.Add(*testUrl, []byte("This is test data"))
+
+	// A fresh Cache over the same directory, as on the next launch.
+	cache = NewCache(5*time.Second, WithDiskTier(dir, time.Hour))
+	defer cache.Close()
+	data, ok := cache.Get(*testUrl)
+	if !ok {
+		t.Fatal("Disk tier did not return an entry written by another Cache.")
+	}
+	if string(data) != "This is test data" {
+		t.Fatalf("Disk tier returned the wrong data: %q", data)
+	}
+}
+
+func TestDiskTierExpiry(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := NewCache(5*time.Second, WithDiskTier(dir, time.Nanosecond))
+	defer cache.Close()
+	cache.Add(*testUrl, []byte{})
+
+	cache = NewCache(5*time.Second, WithDiskTier(dir, time.Nanosecond))
+	defer cache.Close()
+	if _, ok := cache.Get(*testUrl); ok {
+		t.Fatal("Disk tier returned an expired entry.")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("Disk tier left %d expired entries on disk.", len(files))
+	}
+}
+
+// Returns a distinct test URL for the given resource name.
+func urlFor(name string) url.URL {
+	return *testUrl.JoinPath(name)
+}
+
+func TestMaxEntries(t *testing.T) {
+	cache := NewCache(5*time.Second, WithMaxEntries(2))
+	defer cache.Close()
+	cache.Add(urlFor("a"), []byte{})
+	cache.Add(urlFor("b"), []byte{})
+
+	// Touch "a" so that "b" becomes the least recently used entry.
+	cache.Get(urlFor("a"))
+	cache.Add(urlFor("c"), []byte{})
+
+	if cache.Len() != 2 {
+		t.Fatalf("Cache holds %d entries, expected 2.", cache.Len())
+	}
+	if _, ok := cache.Get(urlFor("b")); ok {
+		t.Error("Least recently used entry was not evicted.")
+	}
+	for _, name := range []string{"a", "c"} {
+		if _, ok := cache.Get(urlFor(name)); !ok {
+			t.Errorf("Recently used entry %s was evicted.", name)
+		}
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	cache := NewCache(5*time.Second, WithMaxBytes(10))
+	defer cache.Close()
+	cache.Add(urlFor("a"), make([]byte, 4))
+	cache.Add(urlFor("b"), make([]byte, 4))
+	cache.Add(urlFor("c"), make([]byte, 4))
+
+	if _, ok := cache.Get(urlFor("a")); ok {
+		t.Error("Oldest entry was not evicted to make room.")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Cache holds %d entries, expected 2.", cache.Len())
+	}
+
+	// Replacing an entry must not count its old value towards the bound.
+	cache.Add(urlFor("c"), make([]byte, 7))
+	if cache.Len() != 1 {
+		t.Errorf("Cache holds %d entries after replacing c, expected 1.", cache.Len())
+	}
+
+	// An entry larger than the bound is not kept at all.
+	cache.Add(urlFor("d"), make([]byte, 11))
+	if _, ok := cache.Get(urlFor("d")); ok {
+		t.Error("Cache kept an entry larger than its byte bound.")
+	}
+}
+
+func TestCloseStopsReapLoop(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	caches := make([]*Cache, 10)
+	for i := range caches {
+		caches[i] = NewCache(time.Millisecond)
+	}
+	if runtime.NumGoroutine() < baseline+len(caches) {
+		t.Fatal("Expected every Cache to start a reap goroutine.")
+	}
+
+	for _, cache := range caches {
+		cache.Close()
+		cache.Close()
+	}
+	if leaked := runtime.NumGoroutine() - baseline; leaked > 0 {
+		t.Fatalf("%d reap goroutines still running after Close.", leaked)
+	}
+}
+
+func TestAddWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock))
+	defer cache.Close()
+	cache.AddWithTTL(urlFor("short"), []byte{}, time.Second)
+	cache.AddWithTTL(urlFor("long"), []byte{}, time.Minute)
+
+	clock.Advance(10 * time.Second)
+	if _, ok := cache.Get(urlFor("short")); ok {
+		t.Error("Entry outlived its own TTL.")
+	}
+	if _, ok := cache.Get(urlFor("long")); !ok {
+		t.Error("Entry expired with the Cache's interval instead of its own TTL.")
+	}
+}
+
+func TestStaleWindow(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock), WithStaleWindow(time.Minute))
+	defer cache.Close()
+	cache.Add(*testUrl, []byte("stale data"))
+
+	clock.Advance(10 * time.Second)
 	if _, ok := cache.Get(*testUrl); ok {
-		t.Fatalf("Reap loop failed to remove *test entry")
+		t.Error("Get returned a stale entry.")
+	}
+	entry, ok := cache.Lookup(*testUrl)
+	if !ok || !entry.Stale || string(entry.Val) != "stale data" {
+		t.Errorf("Lookup didn't return the stale entry: %+v, %t", entry, ok)
+	}
+
+	cache.reap()
+	if cache.Len() != 1 {
+		t.Error("Reap removed an entry within the stale window.")
+	}
+
+	clock.Advance(time.Minute)
+	cache.reap()
+	if cache.Len() != 0 {
+		t.Error("Reap kept an entry past the stale window.")
+	}
+}
+
+func TestStaleWindowRereadsDisk(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock), WithStaleWindow(time.Hour), WithDiskTier(dir, 24*time.Hour))
+	defer cache.Close()
+	cache.Add(*testUrl, []byte("disk data"))
+
+	// A fresh Cache over the same directory loads the entry from disk.
+	cache = NewCache(5*time.Second, WithClock(clock), WithStaleWindow(time.Hour), WithDiskTier(dir, 24*time.Hour))
+	defer cache.Close()
+	if _, ok := cache.Get(*testUrl); !ok {
+		t.Fatal("Disk tier did not return an entry written by another Cache.")
+	}
+
+	// Once the memory copy expires, the disk copy is still fresh, so the
+	// entry mustn't be reported as stale.
+	clock.Advance(10 * time.Second)
+	entry, ok := cache.Lookup(*testUrl)
+	if !ok || entry.Stale || string(entry.Val) != "disk data" {
+		t.Errorf("Lookup didn't reload the fresh disk entry: %+v, %t", entry, ok)
+	}
+
+	// Past the disk TTL, the entry is stale but still within the window.
+	clock.Advance(24 * time.Hour)
+	entry, ok = cache.Lookup(*testUrl)
+	if !ok || !entry.Stale {
+		t.Errorf("Lookup didn't return the expired disk entry as stale: %+v, %t", entry, ok)
+	}
+}
+
+func TestExpiredEntriesKeepValidators(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock()
+	validators := Validators{ETag: `"v1"`}
+	cache := NewCache(5*time.Second, WithClock(clock), WithDiskTier(dir, time.Second))
+	defer cache.Close()
+	cache.AddWithValidators(*testUrl, []byte("old data"), time.Second, validators)
+	cache.AddWithTTL(urlFor("plain"), []byte("plain data"), time.Second)
+
+	// With no stale window, only the entry with validators survives expiry.
+	clock.Advance(1500 * time.Millisecond)
+	cache.reap()
+	if cache.Len() != 1 {
+		t.Fatalf("Cache holds %d entries, expected only the one with validators.", cache.Len())
+	}
+	entry, ok := cache.Lookup(*testUrl)
+	if !ok || !entry.Expired || entry.ETag != `"v1"` || string(entry.Val) != "old data" {
+		t.Errorf("Lookup didn't return the expired entry for revalidation: %+v, %t", entry, ok)
+	}
+	if _, ok := cache.Get(*testUrl); ok {
+		t.Error("Get returned an expired entry.")
+	}
+
+	// It's dropped from memory once it has been expired for the disk TTL.
+	clock.Advance(time.Second)
+	cache.reap()
+	if cache.Len() != 0 {
+		t.Errorf("Cache holds %d entries, expected the one with validators to be reaped.", cache.Len())
+	}
+
+	// The same goes for the disk tier, past its TTL.
+	clock.Advance(time.Hour)
+	cache = NewCache(5*time.Second, WithClock(clock), WithDiskTier(dir, time.Second))
+	defer cache.Close()
+	entry, ok = cache.Lookup(*testUrl)
+	if !ok || !entry.Expired || entry.ETag != `"v1"` {
+		t.Errorf("Disk tier dropped the validators of an expired entry: %+v, %t", entry, ok)
+	}
+	if _, ok := cache.Lookup(urlFor("plain")); ok {
+		t.Error("Disk tier kept an expired entry without validators.")
+	}
+}
+
+func TestStats(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock), WithMaxEntries(2))
+	defer cache.Close()
+
+	cache.Add(urlFor("a"), []byte("aaaa"))
+	cache.Get(urlFor("a"))
+	cache.Get(urlFor("b"))
+	cache.Add(urlFor("b"), []byte("bb"))
+	cache.AddWithTTL(urlFor("c"), []byte("c"), time.Second)
+	clock.Advance(2 * time.Second)
+	cache.Get(urlFor("c"))
+
+	expected := Stats{Hits: 1, Misses: 2, Evictions: 1, Expirations: 1, Entries: 1, Bytes: 2}
+	if actual := cache.Stats(); actual != expected {
+		t.Errorf("Wrong stats.\n\tExpected: %+v\n\tFound: %+v", expected, actual)
+	}
+
+	infos := cache.List()
+	if len(infos) != 1 || infos[0].URL != urlFor("b") || infos[0].Age != 2*time.Second || infos[0].Stale {
+		t.Errorf("Wrong entries listed: %+v", infos)
+	}
+
+	cache.Clear()
+	if actual := cache.Stats(); actual != (Stats{}) {
+		t.Errorf("Clear didn't reset the stats: %+v", actual)
+	}
+}
+
+func TestAddNotFound(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock), WithDiskTier(t.TempDir(), time.Hour))
+	defer cache.Close()
+	cache.AddNotFound(*testUrl, time.Second)
+
+	if _, ok := cache.Get(*testUrl); ok {
+		t.Error("Get returned a hit for a not-found entry.")
+	}
+	if entry, ok := cache.Lookup(*testUrl); !ok || !entry.NotFound {
+		t.Errorf("Lookup didn't return the not-found entry: %+v, %t", entry, ok)
+	}
+
+	clock.Advance(time.Second)
+	if _, ok := cache.Lookup(*testUrl); ok {
+		t.Error("Not-found entry outlived its TTL, or was written to disk.")
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	// An earlier session leaves an entry on disk only.
+	previous := NewCache(5*time.Second, WithDiskTier(dir, time.Hour))
+	defer previous.Close()
+	previous.Add(urlFor("on-disk"), []byte("from disk"))
+
+	cache := NewCache(5*time.Second, WithDiskTier(dir, time.Hour))
+	defer cache.Close()
+	cache.Add(urlFor("in-memory"), []byte("from memory"))
+	cache.AddNotFound(urlFor("missing"), time.Minute)
+
+	var snapshot bytes.Buffer
+	n, err := cache.Export(&snapshot)
+	if err != nil {
+		t.Fatalf("Export returned an error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Exported %d entries, expected 2.", n)
+	}
+
+	imported := NewCache(5 * time.Second)
+	defer imported.Close()
+	if n, err := imported.Import(&snapshot, time.Hour); err != nil || n != 2 {
+		t.Fatalf("Import returned %d entries, error %v", n, err)
+	}
+
+	// Keys are compared as url.URL structs, so parsing must reproduce them.
+	base, _ := url.Parse("http://test.go/path/to/endpoint")
+	expected := map[string]string{"on-disk": "from disk", "in-memory": "from memory"}
+	for name, val := range expected {
+		data, ok := imported.Get(*base.JoinPath(name))
+		if !ok || string(data) != val {
+			t.Errorf("Imported cache has %q for %s, expected %q", data, name, val)
+		}
+	}
+	if _, ok := imported.Lookup(urlFor("missing")); ok {
+		t.Error("Not-found entry was exported.")
+	}
+}
+
+func TestImportRejectsGarbage(t *testing.T) {
+	cache := NewCache(5 * time.Second)
+	defer cache.Close()
+	if _, err := cache.Import(strings.NewReader("not a snapshot"), time.Hour); err == nil {
+		t.Fatal("Import accepted a file that isn't a snapshot.")
+	}
+}
+
+func TestValidatorsPersistOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	validators := Validators{ETag: `"v1"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}
+
+	cache := NewCache(5*time.Second, WithDiskTier(dir, time.Hour))
+	defer cache.Close()
+	cache.AddWithValidators(*testUrl, []byte{}, time.Second, validators)
+
+	cache = NewCache(5*time.Second, WithDiskTier(dir, time.Hour))
+	defer cache.Close()
+	entry, ok := cache.Lookup(*testUrl)
+	if !ok || entry.Validators != validators {
+		t.Fatalf("Validators were not restored from disk: %+v", entry.Validators)
+	}
+}
+
+func TestValidatedEntriesReapedWithoutDiskTier(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(5*time.Second, WithClock(clock))
+	defer cache.Close()
+	cache.AddWithValidators(*testUrl, []byte("data"), time.Second, Validators{ETag: `"v1"`})
+
+	clock.Advance(DefaultDiskTTL)
+	cache.reap()
+	if cache.Len() != 1 {
+		t.Fatalf("Entry with validators was reaped before it had been expired for DefaultDiskTTL.")
+	}
+
+	clock.Advance(time.Second)
+	cache.reap()
+	if cache.Len() != 0 {
+		t.Errorf("Cache holds %d entries, expected the expired entry with validators to be reaped.", cache.Len())
 	}
 }
@@ -0,0 +1,155 @@
+package pokecache
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/* Snapshots
+ * A snapshot is a gzipped stream of JSON values: a snapshotHeader followed by
+ * one diskEntry per cached response. Snapshots carry everything a Cache holds,
+ * in memory and on disk, so they can be shipped to a machine without network
+ * access and imported there.
+ */
+
+const (
+	snapshotFormat  = "pokedexcli-cache"
+	snapshotVersion = 1
+)
+
+type snapshotHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+/* Export
+ * Writes a snapshot of every unexpired entry in memory and in the disk tier
+ * to w, returning the number of entries written. Stale and not-found entries
+ * are skipped. Where an entry is both in memory and on disk, the in-memory
+ * copy wins.
+ */
+func (cache *Cache) Export(w io.Writer) (n int, err error) {
+	now := cache.clock.Now()
+	seen := make(map[string]bool)
+	var entries []diskEntry
+
+	cache.Lock()
+	for elem := cache.lru.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*cacheEntry)
+		if entry.notFound || !now.Before(entry.expiresAt) {
+			continue
+		}
+		key := entry.key.String()
+		seen[key] = true
+		entries = append(entries, diskEntry{key, entry.createdAt, entry.val, entry.validators})
+	}
+	cache.Unlock()
+
+	if cache.disk != nil {
+		for _, entry := range cache.disk.all(now) {
+			if !seen[entry.URL] {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	if err := enc.Encode(snapshotHeader{snapshotFormat, snapshotVersion}); err != nil {
+		return 0, fmt.Errorf("Error writing snapshot header: %w", err)
+	}
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return n, fmt.Errorf("Error writing snapshot entry for %s: %w", entry.URL, err)
+		}
+		n++
+	}
+	if err := zw.Close(); err != nil {
+		return n, fmt.Errorf("Error finishing snapshot: %w", err)
+	}
+	return n, nil
+}
+
+/* Import
+ * Reads a snapshot written by Export from r and adds every entry to the
+ * Cache, as AddWithValidators does, returning the number of entries imported.
+ * Imported entries count as freshly created, so they last the full ttl in
+ * memory and the full disk TTL on disk.
+ *
+ * Returns an error if r is not a snapshot, or if it is truncated or corrupt.
+ * Entries read before the error are kept.
+ */
+func (cache *Cache) Import(r io.Reader, ttl time.Duration) (n int, err error) {
+	zr, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return 0, fmt.Errorf("Error reading snapshot: %w", err)
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(zr)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil || header.Format != snapshotFormat {
+		return 0, errors.New("Error reading snapshot: not a pokedexcli cache snapshot")
+	}
+	if header.Version != snapshotVersion {
+		return 0, fmt.Errorf("Error reading snapshot: unsupported version %d", header.Version)
+	}
+
+	for {
+		var entry diskEntry
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("Error reading snapshot entry: %w", err)
+		}
+
+		key, err := url.Parse(entry.URL)
+		if err != nil {
+			return n, fmt.Errorf("Error parsing snapshot entry URL %s: %w", entry.URL, err)
+		}
+		cache.AddWithValidators(*key, entry.Val, ttl, entry.Validators)
+		n++
+	}
+}
+
+// ExportFile writes a snapshot to the file at path, replacing it only once
+// the snapshot is complete.
+func (cache *Cache) ExportFile(path string) (n int, err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("Error creating snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err = cache.Export(tmp)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("Error writing snapshot file: %w", closeErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("Error writing snapshot file: %w", err)
+	}
+	return n, nil
+}
+
+// ImportFile reads a snapshot from the file at path, as Import does.
+func (cache *Cache) ImportFile(path string, ttl time.Duration) (n int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("Error opening snapshot file: %w", err)
+	}
+	defer file.Close()
+	return cache.Import(file, ttl)
+}
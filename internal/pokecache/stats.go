@@ -0,0 +1,101 @@
+package pokecache
+
+import (
+	"container/list"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Stats is a snapshot of a Cache's counters and in-memory contents.
+type Stats struct {
+	Hits        int
+	Misses      int
+	Evictions   int // Entries removed to stay within WithMaxEntries/WithMaxBytes
+	Expirations int // Entries removed because they expired
+	Entries     int
+	Bytes       int
+}
+
+// HitRate returns the fraction of lookups that were hits, or 0 if there
+// have been no lookups.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// EntryInfo describes an entry held in memory, as returned by List.
+type EntryInfo struct {
+	URL      url.URL
+	Age      time.Duration
+	Size     int
+	Stale    bool
+	NotFound bool
+}
+
+// Stats returns the Cache's counters since it was created or last cleared.
+func (cache *Cache) Stats() Stats {
+	cache.Lock()
+	defer cache.Unlock()
+	return Stats{
+		Hits:        cache.hits,
+		Misses:      cache.misses,
+		Evictions:   cache.evictions,
+		Expirations: cache.expirations,
+		Entries:     cache.lru.Len(),
+		Bytes:       cache.bytes,
+	}
+}
+
+// List describes every entry held in memory, sorted by URL.
+func (cache *Cache) List() []EntryInfo {
+	now := cache.clock.Now()
+
+	cache.Lock()
+	infos := make([]EntryInfo, 0, cache.lru.Len())
+	for elem := cache.lru.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*cacheEntry)
+		infos = append(infos, EntryInfo{
+			URL:      entry.key,
+			Age:      now.Sub(entry.createdAt),
+			Size:     len(entry.val),
+			Stale:    !now.Before(entry.expiresAt),
+			NotFound: entry.notFound,
+		})
+	}
+	cache.Unlock()
+
+	slices.SortFunc(infos, func(a, b EntryInfo) int {
+		return strings.Compare(a.URL.String(), b.URL.String())
+	})
+	return infos
+}
+
+// Clear removes every entry from memory and the disk tier, and resets the
+// Cache's counters.
+func (cache *Cache) Clear() {
+	cache.Lock()
+	cache.entries = make(map[url.URL]*list.Element)
+	cache.lru.Init()
+	cache.bytes = 0
+	cache.hits, cache.misses, cache.evictions, cache.expirations = 0, 0, 0, 0
+	cache.Unlock()
+
+	if cache.disk != nil {
+		cache.disk.clear()
+	}
+}
+
+// Records the outcome of a lookup.
+func (cache *Cache) record(hit bool) {
+	cache.Lock()
+	defer cache.Unlock()
+	if hit {
+		cache.hits++
+	} else {
+		cache.misses++
+	}
+}
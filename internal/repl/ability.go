@@ -0,0 +1,58 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+/* Ability command
+ * Takes the name of an ability and prints its English effect, followed by
+ * every Pokemon that can have it, marking those for which it is a hidden
+ * ability. Prints "Ability not found!" if the pokeapi returns a status code
+ * 404.
+ *
+ * Returns an error if the handler fails to coerce the provided arguments as a
+ * string, or if the pokeapi package returns an error.
+ */
+type AbilityHandler struct{}
+
+func (h AbilityHandler) Execute(ctx context.Context, params CommandParams) error {
+	abilityName, ok := params.(string)
+	if !ok {
+		return errors.New("Failed type assertion to string. AbilityHandler requires a string argument")
+	}
+
+	ability, err := client.GetAbility(ctx, abilityName)
+	if err != nil {
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+			fmt.Println("Ability not found!")
+			return err
+		}
+		return fmt.Errorf("Error fetching requested ability: %w", err)
+	}
+
+	fmt.Println(ability.Name)
+	for _, entry := range ability.EffectEntries {
+		if entry.Language.Name == "en" {
+			fmt.Println(entry.Effect)
+			break
+		}
+	}
+
+	if len(ability.Pokemon) == 0 {
+		fmt.Println("No Pokemon have this ability!")
+		return nil
+	}
+	fmt.Println("Pokemon with this ability:")
+	for _, pokemon := range ability.Pokemon {
+		if pokemon.IsHidden {
+			fmt.Printf("\t-%s (hidden)\n", pokemon.Pokemon.Name)
+		} else {
+			fmt.Printf("\t-%s\n", pokemon.Pokemon.Name)
+		}
+	}
+	return nil
+}
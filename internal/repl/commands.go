@@ -1,6 +1,7 @@
 package repl
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -8,15 +9,19 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+	"github.com/caleb-fringer/pokedexcli/internal/pokecache"
 )
 
 type CommandParams any
 
+// Handlers should abandon any work in progress once ctx is cancelled.
 type Handler interface {
-	Execute(params CommandParams) error
+	Execute(ctx context.Context, params CommandParams) error
 }
 
 type Command struct {
@@ -28,6 +33,9 @@ type Command struct {
 var registry map[string]Command
 var pageState MapPagination
 
+// The pokeapi.Client used by every handler. Replaced by DoREPL.
+var client = pokeapi.DefaultClient
+
 const pageSize = 20
 const helpPrompt = "Welcome to the Pokedex!\nUsage:\n\n{{range .}}{{.Name}}: {{.Description}}\n{{end}}"
 
@@ -87,6 +95,41 @@ func init() {
 			Description: "List captured pokemon",
 			Handler:     PokedexHandler{},
 		},
+		"species": {
+			Name:        "species",
+			Description: "Show details of the given Pokemon species",
+			Handler:     SpeciesHandler{},
+		},
+		"evolutions": {
+			Name:        "evolutions",
+			Description: "Show the evolution chain of the given Pokemon",
+			Handler:     EvolutionsHandler{},
+		},
+		"weakness": {
+			Name:        "weakness",
+			Description: "Show how much damage each type deals to the given Pokemon",
+			Handler:     WeaknessHandler{},
+		},
+		"move": {
+			Name:        "move",
+			Description: "Show the power, accuracy and effects of the given move",
+			Handler:     MoveHandler{},
+		},
+		"ability": {
+			Name:        "ability",
+			Description: "Show the effect of the given ability and the Pokemon that can have it",
+			Handler:     AbilityHandler{},
+		},
+		"warm": {
+			Name:        "warm",
+			Description: "Fetch every location-area and the Pokemon in them ahead of time",
+			Handler:     WarmHandler{},
+		},
+		"cache": {
+			Name:        "cache",
+			Description: "Show cache statistics. Use `cache list` to show cached URLs, `cache clear` to empty the cache, or `cache export|import <file>` to save or load a snapshot",
+			Handler:     CacheHandler{DiskTTL: pokecache.DefaultDiskTTL},
+		},
 	}
 }
 
@@ -98,7 +141,7 @@ func init() {
  */
 type ExitHandler struct{}
 
-func (h ExitHandler) Execute(args CommandParams) error {
+func (h ExitHandler) Execute(ctx context.Context, args CommandParams) error {
 	fmt.Println("Closing the Pokedex... Goodbye!")
 	os.Exit(0)
 	return nil
@@ -112,7 +155,7 @@ func (h ExitHandler) Execute(args CommandParams) error {
  */
 type HelpHandler struct{}
 
-func (h HelpHandler) Execute(args CommandParams) error {
+func (h HelpHandler) Execute(ctx context.Context, args CommandParams) error {
 	helpTemplate := template.New("HelpTemplate")
 	helpTemplate = template.Must(helpTemplate.Parse(helpPrompt))
 	err := helpTemplate.Execute(os.Stdout, registry)
@@ -140,20 +183,13 @@ type MapPagination struct {
  */
 type MapHandler struct{}
 
-func (h MapHandler) Execute(params CommandParams) error {
-	queryParams := pageState.Next.Query()
-
-	offset, err := strconv.Atoi(queryParams.Get("offset"))
+func (h MapHandler) Execute(ctx context.Context, params CommandParams) error {
+	offset, limit, err := pageParams(pageState.Next)
 	if err != nil {
-		return fmt.Errorf("Error parsing offset query param to int: %w", err)
-	}
-
-	limit, err := strconv.Atoi(queryParams.Get("limit"))
-	if err != nil {
-		return fmt.Errorf("Error parsing limit query param to int: %w", err)
+		return err
 	}
 
-	response, err := pokeapi.GetLocationAreas(offset, limit)
+	response, err := client.GetLocationAreas(ctx, offset, limit)
 	if err != nil {
 		return err
 	}
@@ -180,25 +216,18 @@ func (h MapHandler) Execute(params CommandParams) error {
  */
 type MapBackHandler struct{}
 
-func (h MapBackHandler) Execute(params CommandParams) error {
+func (h MapBackHandler) Execute(ctx context.Context, params CommandParams) error {
 	if pageState.Previous.Path == "" {
 		fmt.Println("you're on the first page")
 		return nil
 	}
 
-	queryParams := pageState.Previous.Query()
-
-	offset, err := strconv.Atoi(queryParams.Get("offset"))
-	if err != nil {
-		return fmt.Errorf("Error parsing offset query param to int: %w", err)
-	}
-
-	limit, err := strconv.Atoi(queryParams.Get("limit"))
+	offset, limit, err := pageParams(pageState.Previous)
 	if err != nil {
-		return fmt.Errorf("Error parsing limit query param to int: %w", err)
+		return err
 	}
 
-	response, err := pokeapi.GetLocationAreas(offset, limit)
+	response, err := client.GetLocationAreas(ctx, offset, limit)
 	if err != nil {
 		return err
 	}
@@ -217,6 +246,22 @@ func (h MapBackHandler) Execute(params CommandParams) error {
 	return nil
 }
 
+// Extracts the offset and limit query params from a location-area page url.
+func pageParams(pageURL *url.URL) (offset, limit int, err error) {
+	queryParams := pageURL.Query()
+
+	offset, err = strconv.Atoi(queryParams.Get("offset"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error parsing offset query param to int: %w", err)
+	}
+
+	limit, err = strconv.Atoi(queryParams.Get("limit"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error parsing limit query param to int: %w", err)
+	}
+	return offset, limit, nil
+}
+
 func (m *MapPagination) updateState(next, prev string) error {
 	newNext, err := url.Parse(next)
 	if err != nil {
@@ -243,7 +288,7 @@ func (m *MapPagination) updateState(next, prev string) error {
  */
 type ExploreHandler struct{}
 
-func (h ExploreHandler) Execute(params CommandParams) error {
+func (h ExploreHandler) Execute(ctx context.Context, params CommandParams) error {
 	locationAreaName, ok := params.(string)
 	if !ok {
 		return errors.New("Failed type assertion to string. ExploreHandler requires a string argument")
@@ -251,15 +296,13 @@ func (h ExploreHandler) Execute(params CommandParams) error {
 
 	fmt.Printf("Exploring %v...\n", locationAreaName)
 
-	response, err := pokeapi.GetLocationArea(locationAreaName)
+	response, err := client.GetLocationArea(ctx, locationAreaName)
 	if err != nil {
-		switch err.(type) {
-		case pokeapi.ResourceNotFoundError:
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
 			fmt.Println("Location not found!")
 			return err
-		default:
-			return fmt.Errorf("Error fetching requested location-area: %w", err)
 		}
+		return fmt.Errorf("Error fetching requested location-area: %w", err)
 	}
 
 	fmt.Println("Found Pokemon:")
@@ -291,7 +334,7 @@ var caughtPokemon = make(map[string]pokeapi.Pokemon)
  */
 type CatchHandler struct{}
 
-func (h CatchHandler) Execute(params CommandParams) error {
+func (h CatchHandler) Execute(ctx context.Context, params CommandParams) error {
 	pokemonName, ok := params.(string)
 	if !ok {
 		return errors.New("Failed type assertion to string. CatchHandler requires a string argument")
@@ -302,15 +345,13 @@ func (h CatchHandler) Execute(params CommandParams) error {
 		return nil
 	}
 
-	response, err := pokeapi.GetPokemon(pokemonName)
+	response, err := client.GetPokemon(ctx, pokemonName)
 	if err != nil {
-		switch err.(type) {
-		case pokeapi.ResourceNotFoundError:
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
 			fmt.Println("Pokemon not found!")
 			return err
-		default:
-			return fmt.Errorf("Error fetching requested Pokemon: %w", err)
 		}
+		return fmt.Errorf("Error fetching requested Pokemon: %w", err)
 	}
 
 	fmt.Printf("Throwing a Pokeball at %s...\n", pokemonName)
@@ -369,13 +410,15 @@ var inspectTemplateString string = ("Name: {{.Name}}\n" +
 	"Stats:\n" +
 	"{{range .Stats}}\t-{{.Stat.Name}}: {{.BaseStat}}\n{{end}}" +
 	"Types:\n" +
-	"{{range .Types}}\t-{{.Type.Name}}\n{{end}}")
+	"{{range .Types}}\t-{{.Type.Name}}\n{{end}}" +
+	"Abilities:\n" +
+	"{{range .Abilities}}\t-{{.Ability.Name}}{{if .IsHidden}} (hidden){{end}}\n{{end}}")
 
 var inspectPokemonTemplate = template.Must(template.New("inspectPokemon").Parse(inspectTemplateString))
 
 type InspectHandler struct{}
 
-func (h InspectHandler) Execute(params CommandParams) error {
+func (h InspectHandler) Execute(ctx context.Context, params CommandParams) error {
 	pokemonName, ok := params.(string)
 
 	if !ok {
@@ -396,7 +439,7 @@ func (h InspectHandler) Execute(params CommandParams) error {
 
 type PokedexHandler struct{}
 
-func (h PokedexHandler) Execute(params CommandParams) error {
+func (h PokedexHandler) Execute(ctx context.Context, params CommandParams) error {
 	if len(caughtPokemon) < 1 {
 		fmt.Println("You haven't caught any Pokemon!")
 		return nil
@@ -408,3 +451,99 @@ func (h PokedexHandler) Execute(params CommandParams) error {
 	}
 	return nil
 }
+
+/* Cache command
+ * Takes an optional subcommand ([]string):
+ *    -With no subcommand, prints the client cache's statistics.
+ *    -`list` prints every cached URL with its age and size.
+ *    -`clear` empties the cache, including its disk tier.
+ *    -`export <file>` writes a snapshot of the cache to file.
+ *    -`import <file>` loads a snapshot written by `export`. Imported entries
+ *     stay in memory for ImportTTL(DiskTTL).
+ *
+ * Returns an error if the params argument cannot be asserted as a []string,
+ * or if exporting or importing a snapshot fails.
+ */
+type CacheHandler struct {
+	DiskTTL time.Duration // The TTL of the client cache's disk tier
+}
+
+func (h CacheHandler) Execute(ctx context.Context, params CommandParams) error {
+	args, ok := params.([]string)
+	if !ok {
+		return errors.New("Failed type assertion to []string. CacheHandler requires a []string argument")
+	}
+
+	cache := client.Cache()
+	if len(args) == 0 {
+		stats := cache.Stats()
+		fmt.Println("Cache statistics:")
+		fmt.Printf("\t-hits: %d\n", stats.Hits)
+		fmt.Printf("\t-misses: %d\n", stats.Misses)
+		fmt.Printf("\t-hit rate: %.1f%%\n", 100*stats.HitRate())
+		fmt.Printf("\t-evictions: %d\n", stats.Evictions)
+		fmt.Printf("\t-expirations: %d\n", stats.Expirations)
+		fmt.Printf("\t-entries: %d\n", stats.Entries)
+		fmt.Printf("\t-bytes: %d\n", stats.Bytes)
+		return nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		infos := cache.List()
+		if len(infos) == 0 {
+			fmt.Println("The cache is empty!")
+			return nil
+		}
+		for _, info := range infos {
+			notes := ""
+			if info.NotFound {
+				notes += " (not found)"
+			}
+			if info.Stale {
+				notes += " (stale)"
+			}
+			fmt.Printf("\t-%s: %s old, %d bytes%s\n",
+				info.URL.String(), info.Age.Round(time.Second), info.Size, notes)
+		}
+	case "clear":
+		cache.Clear()
+		fmt.Println("Cache cleared!")
+	case "export", "import":
+		if len(args) < 2 {
+			fmt.Printf("Please provide a file to %s!\n", args[0])
+			return nil
+		}
+		return snapshot(cache, strings.ToLower(args[0]), args[1], ImportTTL(h.DiskTTL))
+	default:
+		fmt.Printf("Unknown cache subcommand %s. Try `cache`, `cache list`, `cache clear`, `cache export` or `cache import`.\n", args[0])
+	}
+	return nil
+}
+
+// Exports or imports a snapshot of cache, reporting the number of entries.
+// Imported entries stay in memory for ttl.
+func snapshot(cache *pokecache.Cache, direction, path string, ttl time.Duration) error {
+	if direction == "export" {
+		n, err := cache.ExportFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Exported %d entries to %s\n", n, path)
+		return nil
+	}
+
+	n, err := cache.ImportFile(path, ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d entries from %s\n", n, path)
+	return nil
+}
+
+// ImportTTL returns how long entries imported from a snapshot stay in memory:
+// as long as a fetched resource would, but no longer than the disk tier, with
+// a TTL of diskTTL, keeps them.
+func ImportTTL(diskTTL time.Duration) time.Duration {
+	return min(diskTTL, pokeapi.DefaultResourceTTL)
+}
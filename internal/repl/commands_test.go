@@ -0,0 +1,341 @@
+package repl
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapitest"
+	"github.com/caleb-fringer/pokedexcli/internal/pokecache"
+)
+
+// Points the REPL at a fixture server for the rest of the test, starting
+// from the first page of location-areas with nothing caught.
+func useFixtures(t *testing.T) *pokeapitest.Server {
+	t.Helper()
+	fixtures := pokeapitest.NewServer(t)
+	c := pokeapi.NewClient(pokeapi.WithBaseURL(fixtures.BaseURL()), pokeapi.WithRateLimit(0, 0))
+
+	oldClient, oldPageState, oldCaught := client, pageState, caughtPokemon
+	client = c
+	first, _ := url.Parse(fixtures.URL + "/location-area?offset=0&limit=20")
+	pageState = MapPagination{Next: first, Previous: &url.URL{}}
+	caughtPokemon = make(map[string]pokeapi.Pokemon)
+	t.Cleanup(func() {
+		c.Close()
+		client, pageState, caughtPokemon = oldClient, oldPageState, oldCaught
+	})
+	return fixtures
+}
+
+// Runs a REPL command line and returns what it printed.
+func run(t *testing.T, line string) string {
+	t.Helper()
+	tokens := cleanInput(line)
+	return captureStdout(t, func() {
+		doCommand(context.Background(), tokens[0], tokens[1:], strings.Fields(line)[1:])
+	})
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Error creating pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-output
+}
+
+func TestMapPaging(t *testing.T) {
+	useFixtures(t)
+
+	first := run(t, "map")
+	if !strings.HasPrefix(first, "canalave-city-area\n") || strings.Count(first, "\n") != 21 {
+		t.Errorf("map printed the wrong first page:\n%s", first)
+	}
+
+	second := run(t, "map")
+	if !strings.HasPrefix(second, "mt-coronet-1f-route-216\n") || strings.Contains(second, "canalave-city-area") {
+		t.Errorf("map printed the wrong second page:\n%s", second)
+	}
+
+	back := run(t, "mapb")
+	if back != first {
+		t.Errorf("mapb didn't return to the first page.\n\tExpected:\n%s\n\tFound:\n%s", first, back)
+	}
+	if out := run(t, "mapb"); out != "you're on the first page\n" {
+		t.Errorf("mapb on the first page printed: %q", out)
+	}
+}
+
+func TestExplore(t *testing.T) {
+	useFixtures(t)
+
+	out := run(t, "explore pastoria-city-area")
+	if !strings.Contains(out, "Found Pokemon:\n\t- tentacool\n") {
+		t.Errorf("explore printed the wrong Pokemon:\n%s", out)
+	}
+
+	out = run(t, "explore nowhere")
+	if !strings.Contains(out, "Location not found!") {
+		t.Errorf("explore of an unknown location-area printed:\n%s", out)
+	}
+}
+
+func TestCatchAndInspect(t *testing.T) {
+	fixtures := useFixtures(t)
+
+	if out := run(t, "inspect magikarp"); out != "You haven't caught magikarp yet!\n" {
+		t.Errorf("inspect before catching printed: %q", out)
+	}
+
+	for i := 0; i < 50 && caughtPokemon["magikarp"].Name == ""; i++ {
+		run(t, "catch magikarp")
+	}
+	if caughtPokemon["magikarp"].Name == "" {
+		t.Fatalf("Failed to catch magikarp in 50 throws")
+	}
+	if n := fixtures.Requests("/pokemon/magikarp"); n != 1 {
+		t.Errorf("Expected magikarp to be fetched once, found %d requests", n)
+	}
+
+	out := run(t, "inspect magikarp")
+	for _, want := range []string{"Name: magikarp\n", "\t-speed: 80\n", "Types:\n\t-water\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("inspect output is missing %q:\n%s", want, out)
+		}
+	}
+
+	if out := run(t, "catch missingno"); !strings.Contains(out, "Pokemon not found!") {
+		t.Errorf("catch of an unknown Pokemon printed:\n%s", out)
+	}
+}
+
+func TestSpecies(t *testing.T) {
+	useFixtures(t)
+
+	out := run(t, "species pikachu")
+	for _, want := range []string{
+		"pikachu, the Mouse Pokémon (#25)\n",
+		"\t-capture rate: 190\n",
+		"\t-egg groups: ground, fairy\n",
+		"\t-evolves from: pichu\n",
+		"When several of these POKéMON gather, their electricity could build and cause lightning storms.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("species output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "legendary") {
+		t.Errorf("pikachu shouldn't be legendary:\n%s", out)
+	}
+
+	if out := run(t, "species mewtwo"); !strings.Contains(out, "\t-legendary\n") {
+		t.Errorf("mewtwo should be legendary:\n%s", out)
+	}
+	if out := run(t, "species missingno"); !strings.Contains(out, "Species not found!") {
+		t.Errorf("species of an unknown Pokemon printed:\n%s", out)
+	}
+}
+
+func TestEvolutions(t *testing.T) {
+	useFixtures(t)
+
+	out := run(t, "evolutions pikachu")
+	expected := "pichu\n\t-pikachu (level up with happiness 220)\n\t\t-raichu (use thunder-stone)\n"
+	if out != expected {
+		t.Errorf("Wrong evolution tree for pikachu.\n\tExpected:\n%s\n\tFound:\n%s", expected, out)
+	}
+
+	out = run(t, "evolutions eevee")
+	for _, want := range []string{
+		"eevee\n",
+		"\t-vaporeon (use water-stone)\n",
+		"\t-umbreon (level up with happiness 160 during the night)\n",
+		"\t-leafeon (level up at eterna-forest or use leaf-stone)\n",
+		"\t-sylveon (level up knowing a fairy move with affection 2)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("evolutions output is missing %q:\n%s", want, out)
+		}
+	}
+
+	if out := run(t, "evolutions magikarp"); !strings.Contains(out, "\t-gyarados (level 20)\n") {
+		t.Errorf("Wrong evolution tree for magikarp:\n%s", out)
+	}
+
+	// giratina-altered is a Pokemon, not a species, so it's resolved through
+	// the Pokemon's species.
+	if out := run(t, "evolutions giratina-altered"); out != "giratina\n" {
+		t.Errorf("Wrong evolution tree for giratina-altered: %q", out)
+	}
+	if out := run(t, "evolutions missingno"); out != "Pokemon not found!\n" {
+		t.Errorf("evolutions of an unknown Pokemon printed: %q", out)
+	}
+}
+
+func TestWeakness(t *testing.T) {
+	useFixtures(t)
+
+	out := run(t, "weakness gyarados")
+	expected := "Damage taken by gyarados (water/flying):\n" +
+		"\t-4x: electric\n" +
+		"\t-2x: rock\n" +
+		"\t-1x: normal, flying, poison, ghost, grass, psychic, ice, dragon, dark, fairy\n" +
+		"\t-0.5x: fighting, bug, steel, fire, water\n" +
+		"\t-0x: ground\n"
+	if out != expected {
+		t.Errorf("Wrong weaknesses for gyarados.\n\tExpected:\n%s\n\tFound:\n%s", expected, out)
+	}
+
+	out = run(t, "weakness tentacool")
+	if !strings.Contains(out, "\t-2x: ground, electric, psychic\n") {
+		t.Errorf("Wrong weaknesses for tentacool:\n%s", out)
+	}
+}
+
+func TestEffectivenessTable(t *testing.T) {
+	table := make(effectivenessTable)
+	for _, data := range []string{
+		`{"name": "grass", "damage_relations": {"half_damage_from": [{"name": "grass"}], "double_damage_from": [{"name": "fire"}]}}`,
+		`{"name": "poison", "damage_relations": {"half_damage_from": [{"name": "grass"}]}}`,
+	} {
+		var defending pokeapi.Type
+		if err := json.Unmarshal([]byte(data), &defending); err != nil {
+			t.Fatalf("Error decoding type: %v", err)
+		}
+		table.add(defending)
+	}
+
+	if m := table.multiplier("grass", []string{"grass", "poison"}); m != 0.25 {
+		t.Errorf("Expected grass to deal 0.25x to grass/poison, found %vx", m)
+	}
+	if m := table.multiplier("fire", []string{"grass", "poison"}); m != 2 {
+		t.Errorf("Expected fire to deal 2x to grass/poison, found %vx", m)
+	}
+	if m := table.multiplier("water", []string{"grass", "poison"}); m != 1 {
+		t.Errorf("Expected a type missing from the table to deal 1x, found %vx", m)
+	}
+}
+
+func TestMove(t *testing.T) {
+	useFixtures(t)
+
+	out := run(t, "move thunderbolt")
+	expected := "thunderbolt (electric, special)\n" +
+		"\t-power: 90\n" +
+		"\t-accuracy: 100%\n" +
+		"\t-pp: 15\n" +
+		"\t-priority: +0\n" +
+		"\t-ailment: paralysis (10% chance)\n" +
+		"Has a 10% chance to paralyze the target.\n"
+	if out != expected {
+		t.Errorf("Wrong details for thunderbolt.\n\tExpected:\n%s\n\tFound:\n%s", expected, out)
+	}
+
+	cases := []struct {
+		move string
+		want string
+	}{
+		{"swords-dance", "\t-power: -\n\t-accuracy: -\n"},
+		{"brave-bird", "\t-recoil: 33% of damage dealt\n"},
+		{"night-slash", "\t-crit rate: +1\n"},
+		{"splash-dance", "Move not found!\n"},
+	}
+	for _, c := range cases {
+		if out := run(t, "move "+c.move); !strings.Contains(out, c.want) {
+			t.Errorf("move %s output is missing %q:\n%s", c.move, c.want, out)
+		}
+	}
+}
+
+func TestAbility(t *testing.T) {
+	useFixtures(t)
+
+	out := run(t, "ability lightning-rod")
+	for _, want := range []string{
+		"lightning-rod\n",
+		"This Pokémon's Special Attack rises one stage",
+		"Pokemon with this ability:\n\t-pikachu (hidden)\n\t-raichu (hidden)\n\t-rhyhorn\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ability output is missing %q:\n%s", want, out)
+		}
+	}
+
+	if out := run(t, "ability wonder-guard"); !strings.Contains(out, "Ability not found!") {
+		t.Errorf("ability of an unknown ability printed:\n%s", out)
+	}
+}
+
+func TestInspectAbilities(t *testing.T) {
+	useFixtures(t)
+
+	pikachu, err := client.GetPokemon(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatalf("GetPokemon returned an error: %v", err)
+	}
+	caughtPokemon["pikachu"] = pikachu
+
+	out := run(t, "inspect pikachu")
+	if !strings.HasSuffix(out, "Abilities:\n\t-static\n\t-lightning-rod (hidden)\n") {
+		t.Errorf("inspect should list pikachu's abilities:\n%s", out)
+	}
+}
+
+func TestCacheImportTTL(t *testing.T) {
+	fixtures := useFixtures(t)
+	path := t.TempDir() + "/pokedex.snapshot"
+
+	run(t, "catch pikachu")
+	if out := run(t, "cache export "+path); out != "Exported 1 entries to "+path+"\n" {
+		t.Fatalf("cache export printed: %q", out)
+	}
+
+	key, _ := url.Parse(fixtures.URL + "/pokemon/pikachu")
+	cases := []struct {
+		diskTTL time.Duration
+		want    time.Duration
+	}{
+		{pokecache.DefaultDiskTTL, pokeapi.DefaultResourceTTL},
+		{time.Minute, time.Minute},
+	}
+	for _, c := range cases {
+		client.Cache().Clear()
+		handler := CacheHandler{DiskTTL: c.diskTTL}
+		out := captureStdout(t, func() {
+			if err := handler.Execute(context.Background(), []string{"import", path}); err != nil {
+				t.Errorf("cache import returned an error: %v", err)
+			}
+		})
+		if out != "Imported 1 entries from "+path+"\n" {
+			t.Errorf("cache import printed: %q", out)
+		}
+
+		entry, ok := client.Cache().Lookup(*key)
+		if !ok {
+			t.Fatalf("pikachu wasn't imported")
+		}
+		if ttl := entry.ExpiresAt.Sub(entry.CreatedAt); ttl != c.want {
+			t.Errorf("With a disk TTL of %v, expected imported entries to stay in memory for %v, found %v",
+				c.diskTTL, c.want, ttl)
+		}
+	}
+}
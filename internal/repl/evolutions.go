@@ -0,0 +1,178 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+/* Evolutions command
+ * Takes the name of a Pokemon, looks up its species' evolution chain, and
+ * prints the whole chain as a tree. Each species is indented under the one
+ * it evolves from, followed by the ways it evolves, e.g.
+ *
+ *     pichu
+ *         -pikachu (level up with happiness 220)
+ *             -raichu (use thunder-stone)
+ *
+ * Names of Pokemon that differ from their species, such as
+ * giratina-altered, are resolved through the Pokemon's species. Prints
+ * "Pokemon not found!" if neither a species nor a Pokemon has the name.
+ *
+ * Returns an error if the handler fails to coerce the provided arguments as a
+ * string, if the species' evolution chain url has no id, or if the pokeapi
+ * package returns an error.
+ */
+type EvolutionsHandler struct{}
+
+func (h EvolutionsHandler) Execute(ctx context.Context, params CommandParams) error {
+	pokemonName, ok := params.(string)
+	if !ok {
+		return errors.New("Failed type assertion to string. EvolutionsHandler requires a string argument")
+	}
+
+	species, err := speciesOf(ctx, pokemonName)
+	if err != nil {
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+			fmt.Println("Pokemon not found!")
+			return err
+		}
+		return fmt.Errorf("Error fetching the species of %s: %w", pokemonName, err)
+	}
+
+	id, err := pokeapi.ResourceID(species.EvolutionChain.URL)
+	if err != nil {
+		return err
+	}
+	chain, err := client.GetEvolutionChain(ctx, id)
+	if err != nil {
+		return fmt.Errorf("Error fetching the evolution chain of %s: %w", pokemonName, err)
+	}
+
+	printChainLink(chain.Chain, 0)
+	return nil
+}
+
+// Fetches the species named `name`, or failing that, the species of the
+// Pokemon named `name`.
+func speciesOf(ctx context.Context, name string) (pokeapi.PokemonSpecies, error) {
+	species, err := client.GetPokemonSpecies(ctx, name)
+	if !errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+		return species, err
+	}
+
+	pokemon, pokemonErr := client.GetPokemon(ctx, name)
+	if pokemonErr != nil {
+		if errors.As(pokemonErr, &pokeapi.ResourceNotFoundError{}) {
+			return species, err
+		}
+		return species, pokemonErr
+	}
+	return client.GetPokemonSpecies(ctx, pokemon.Species.Name)
+}
+
+// Prints link indented by depth, then the species it evolves into one level
+// deeper.
+func printChainLink(link pokeapi.ChainLink, depth int) {
+	if depth == 0 {
+		fmt.Println(link.Species.Name)
+	} else {
+		methods := make([]string, 0, len(link.EvolutionDetails))
+		for _, detail := range link.EvolutionDetails {
+			methods = append(methods, describeEvolution(detail))
+		}
+		fmt.Printf("%s-%s", strings.Repeat("\t", depth), link.Species.Name)
+		if len(methods) > 0 {
+			fmt.Printf(" (%s)", strings.Join(methods, " or "))
+		}
+		fmt.Println()
+	}
+
+	for _, next := range link.EvolvesTo {
+		printChainLink(next, depth+1)
+	}
+}
+
+/* describeEvolution
+ * Describes one way to evolve in words, starting from its trigger and
+ * followed by every condition that applies, e.g. "level up with happiness
+ * 160 during the day" or "trade holding metal-coat".
+ */
+func describeEvolution(detail pokeapi.EvolutionDetail) string {
+	var trigger string
+	switch detail.Trigger.Name {
+	case "level-up":
+		trigger = "level up"
+		if detail.MinLevel > 0 {
+			trigger = fmt.Sprintf("level %d", detail.MinLevel)
+		}
+	case "use-item":
+		trigger = "use " + detail.Item.Name
+	case "trade":
+		trigger = "trade"
+	default:
+		trigger = strings.ReplaceAll(detail.Trigger.Name, "-", " ")
+	}
+
+	var conditions []string
+	if detail.HeldItem.Name != "" {
+		conditions = append(conditions, "holding "+detail.HeldItem.Name)
+	}
+	if detail.TradeSpecies.Name != "" {
+		conditions = append(conditions, "for "+detail.TradeSpecies.Name)
+	}
+	if detail.KnownMove.Name != "" {
+		conditions = append(conditions, "knowing "+detail.KnownMove.Name)
+	}
+	if detail.KnownMoveType.Name != "" {
+		conditions = append(conditions, "knowing a "+detail.KnownMoveType.Name+" move")
+	}
+	if detail.MinHappiness > 0 {
+		conditions = append(conditions, fmt.Sprintf("with happiness %d", detail.MinHappiness))
+	}
+	if detail.MinAffection > 0 {
+		conditions = append(conditions, fmt.Sprintf("with affection %d", detail.MinAffection))
+	}
+	if detail.MinBeauty > 0 {
+		conditions = append(conditions, fmt.Sprintf("with beauty %d", detail.MinBeauty))
+	}
+	if detail.Location.Name != "" {
+		conditions = append(conditions, "at "+detail.Location.Name)
+	}
+	if detail.TimeOfDay != "" {
+		conditions = append(conditions, "during the "+detail.TimeOfDay)
+	}
+	if detail.NeedsOverworldRain {
+		conditions = append(conditions, "in the rain")
+	}
+	if detail.PartySpecies.Name != "" {
+		conditions = append(conditions, "with "+detail.PartySpecies.Name+" in the party")
+	}
+	if detail.PartyType.Name != "" {
+		conditions = append(conditions, "with a "+detail.PartyType.Name+" type in the party")
+	}
+	if detail.RelativePhysicalStats != nil {
+		switch {
+		case *detail.RelativePhysicalStats > 0:
+			conditions = append(conditions, "when attack > defense")
+		case *detail.RelativePhysicalStats < 0:
+			conditions = append(conditions, "when attack < defense")
+		default:
+			conditions = append(conditions, "when attack = defense")
+		}
+	}
+	switch detail.Gender {
+	case 1:
+		conditions = append(conditions, "if female")
+	case 2:
+		conditions = append(conditions, "if male")
+	}
+	if detail.TurnUpsideDown {
+		conditions = append(conditions, "holding the console upside down")
+	}
+
+	return strings.Join(append([]string{trigger}, conditions...), " ")
+}
@@ -0,0 +1,94 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+/* Move command
+ * Takes the name of a move and prints its type, damage class, power,
+ * accuracy, pp and priority, any ailment, critical hit, drain or flinch
+ * effects it has, and its English effect text. Prints "Move not found!" if
+ * the pokeapi returns a status code 404.
+ *
+ * Returns an error if the handler fails to coerce the provided arguments as a
+ * string, or if the pokeapi package returns an error.
+ */
+type MoveHandler struct{}
+
+func (h MoveHandler) Execute(ctx context.Context, params CommandParams) error {
+	moveName, ok := params.(string)
+	if !ok {
+		return errors.New("Failed type assertion to string. MoveHandler requires a string argument")
+	}
+
+	move, err := client.GetMove(ctx, moveName)
+	if err != nil {
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+			fmt.Println("Move not found!")
+			return err
+		}
+		return fmt.Errorf("Error fetching requested move: %w", err)
+	}
+
+	fmt.Printf("%s (%s, %s)\n", move.Name, move.Type.Name, move.DamageClass.Name)
+	fmt.Printf("\t-power: %s\n", optionalInt(move.Power, ""))
+	fmt.Printf("\t-accuracy: %s\n", optionalInt(move.Accuracy, "%"))
+	fmt.Printf("\t-pp: %d\n", move.PP)
+	fmt.Printf("\t-priority: %+d\n", move.Priority)
+
+	meta := move.Meta
+	if meta.Ailment.Name != "" && meta.Ailment.Name != "none" {
+		if meta.AilmentChance > 0 {
+			fmt.Printf("\t-ailment: %s (%d%% chance)\n", meta.Ailment.Name, meta.AilmentChance)
+		} else {
+			fmt.Printf("\t-ailment: %s\n", meta.Ailment.Name)
+		}
+	}
+	if meta.CritRate > 0 {
+		fmt.Printf("\t-crit rate: +%d\n", meta.CritRate)
+	}
+	if meta.Drain > 0 {
+		fmt.Printf("\t-drain: %d%% of damage dealt\n", meta.Drain)
+	} else if meta.Drain < 0 {
+		fmt.Printf("\t-recoil: %d%% of damage dealt\n", -meta.Drain)
+	}
+	if meta.FlinchChance > 0 {
+		fmt.Printf("\t-flinch chance: %d%%\n", meta.FlinchChance)
+	}
+
+	if effect := englishEffect(move); effect != "" {
+		fmt.Println(effect)
+	}
+	return nil
+}
+
+// Formats a value that PokeAPI may leave null, such as the power of a status
+// move, as "-".
+func optionalInt(value *int, unit string) string {
+	if value == nil {
+		return "-"
+	}
+	return strconv.Itoa(*value) + unit
+}
+
+// Returns the move's English short effect with its effect chance filled in,
+// or "" if it has none.
+func englishEffect(move pokeapi.Move) string {
+	for _, entry := range move.EffectEntries {
+		if entry.Language.Name != "en" {
+			continue
+		}
+		effect := entry.ShortEffect
+		if move.EffectChance != nil {
+			effect = strings.ReplaceAll(effect, "$effect_chance", strconv.Itoa(*move.EffectChance))
+		}
+		return effect
+	}
+	return ""
+}
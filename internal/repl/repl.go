@@ -2,10 +2,15 @@ package repl
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
 )
@@ -18,35 +23,113 @@ func init() {
 	tokenizer = regexp.MustCompile("[[:alpha:]]+(?:-[[:alnum:]]+)*")
 }
 
-func DoREPL() {
-	scanner := bufio.NewScanner(os.Stdin)
+// DoREPL runs the Pokedex REPL, fetching resources with the given client.
+// diskTTL is the TTL of the disk tier of the client's cache, if it has one.
+func DoREPL(c *pokeapi.Client, diskTTL time.Duration) {
+	client = c
+	cacheCommand := registry["cache"]
+	cacheCommand.Handler = CacheHandler{DiskTTL: diskTTL}
+	registry["cache"] = cacheCommand
+
+	// Catch Ctrl-C so it cancels the command in flight instead of killing
+	// the process.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	prompt(os.Stdin, interrupts)
+	fmt.Println()
+	os.Exit(1)
+}
+
+/* prompt
+ * Reads command lines from in and runs them until in is exhausted. An
+ * interrupt while a command runs cancels it, as runInterruptible does; an
+ * interrupt at the idle prompt abandons the line being typed and prompts
+ * again.
+ */
+func prompt(in io.Reader, interrupts <-chan os.Signal) {
+	// Scan in the background, so the prompt can react to interrupts while
+	// waiting for a line.
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
 
 	for {
 		fmt.Print("Pokedex > ")
-		if ok := scanner.Scan(); !ok {
+		var line string
+		select {
+		case <-interrupts:
 			fmt.Println()
-			os.Exit(1)
+			continue
+		case next, ok := <-lines:
+			if !ok {
+				return
+			}
+			line = next
 		}
 
-		line := scanner.Text()
 		tokens := cleanInput(line)
-
 		if len(tokens) == 0 {
 			continue
 		}
 
 		cmd := tokens[0]
 		args := tokens[1:]
-		doCommand(cmd, args)
+		rawArgs := strings.Fields(line)[1:]
+		runInterruptible(interrupts, func(ctx context.Context) {
+			doCommand(ctx, cmd, args, rawArgs)
+		})
 	}
 }
 
+/* runInterruptible
+ * Runs fn with a context that is cancelled as soon as a signal arrives on
+ * interrupts. Any interrupt left over from while the prompt was idle is
+ * discarded first, so it can't cancel the new command.
+ */
+func runInterruptible(interrupts <-chan os.Signal, fn func(ctx context.Context)) {
+	select {
+	case <-interrupts:
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Wait for the watcher to exit, so it can't swallow an interrupt meant
+	// for the next command.
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-interrupts:
+			cancel()
+		case <-done:
+		}
+	}()
+
+	fn(ctx)
+	close(done)
+	<-stopped
+}
+
 func cleanInput(text string) (tokens []string) {
 	lower := strings.ToLower(text)
 	return tokenizer.FindAllString(lower, -1)
 }
 
-func doCommand(command string, args []string) bool {
+/* doCommand
+ * Runs the named command. args are the cleaned tokens following the command,
+ * while rawArgs are the whitespace-separated words as typed, for commands
+ * such as `cache export <file>` that take file paths.
+ */
+func doCommand(ctx context.Context, command string, args, rawArgs []string) bool {
 	// Fetch the command structure, returning if not found.
 	commandStruct, ok := registry[command]
 	if !ok {
@@ -71,15 +154,78 @@ func doCommand(command string, args []string) bool {
 			return false
 		}
 		params = args[0]
+	case "weakness", "evolutions":
+		if len(args) < 1 {
+			fmt.Println("Please provide a Pokemon!")
+			return false
+		}
+		params = args[0]
+	case "move":
+		if len(args) < 1 {
+			fmt.Println("Please provide a move!")
+			return false
+		}
+		params = args[0]
+	case "ability":
+		if len(args) < 1 {
+			fmt.Println("Please provide an ability!")
+			return false
+		}
+		params = args[0]
+	case "species":
+		if len(args) < 1 {
+			fmt.Println("Please provide a Pokemon species!")
+			return false
+		}
+		params = args[0]
+	case "cache":
+		params = rawArgs
 	}
 
-	err := commandStruct.Execute(params)
+	err := commandStruct.Execute(ctx, params)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("\nCommand cancelled.")
+			return false
+		}
 		// Ignore ResourceNotFoundErrors, they do not need to be handled.
-		if _, ok := err.(pokeapi.ResourceNotFoundError); !ok {
-			fmt.Println(err)
+		if !errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+			fmt.Println(describeError(err))
 		}
 		return false
 	}
 	return true
 }
+
+// Explains pokeapi failures in terms the user can act on, falling back to
+// the error itself.
+func describeError(err error) string {
+	var (
+		timeout     pokeapi.TimeoutError
+		network     pokeapi.NetworkError
+		rateLimited pokeapi.RateLimitedError
+		server      pokeapi.ServerError
+		decode      pokeapi.DecodeError
+	)
+
+	switch {
+	case errors.As(err, &timeout):
+		return "PokeAPI took too long to respond. Try again in a moment!"
+	case errors.As(err, &network):
+		return "Couldn't reach PokeAPI. Check your internet connection!"
+	case errors.As(err, &rateLimited):
+		if rateLimited.RetryAfter > 0 {
+			return fmt.Sprintf("PokeAPI is rate limiting us. Try again in %v!", rateLimited.RetryAfter)
+		}
+		return "PokeAPI is rate limiting us. Try again later!"
+	case errors.As(err, &server):
+		if server.RetryAfter > 0 {
+			return fmt.Sprintf("PokeAPI is having trouble (status %d). Try again in %v!", server.StatusCode, server.RetryAfter)
+		}
+		return fmt.Sprintf("PokeAPI is having trouble (status %d). Try again later!", server.StatusCode)
+	case errors.As(err, &decode):
+		return fmt.Sprintf("PokeAPI sent a response we couldn't understand from %s", decode.URL)
+	default:
+		return err.Error()
+	}
+}
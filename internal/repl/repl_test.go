@@ -1,6 +1,12 @@
 package repl
 
-import "testing"
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
 
 func TestCleanInput(t *testing.T) {
 	testCases := []struct {
@@ -45,3 +51,57 @@ func TestCleanInput(t *testing.T) {
 	}
 	t.Log("Done! All tests passed :)")
 }
+
+func TestRunInterruptible(t *testing.T) {
+	interrupts := make(chan os.Signal, 1)
+
+	// A stale interrupt from the idle prompt must not cancel the command.
+	interrupts <- os.Interrupt
+	runInterruptible(interrupts, func(ctx context.Context) {
+		if ctx.Err() != nil {
+			t.Error("Command was cancelled by an interrupt sent before it started")
+		}
+	})
+
+	runInterruptible(interrupts, func(ctx context.Context) {
+		interrupts <- os.Interrupt
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Error("Command was not cancelled by an interrupt")
+		}
+	})
+}
+
+func TestPromptInterrupt(t *testing.T) {
+	in, w := io.Pipe()
+	interrupts := make(chan os.Signal, 1)
+
+	out := captureStdout(t, func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			prompt(in, interrupts)
+		}()
+
+		// An interrupt at the idle prompt should prompt again, not exit or
+		// cancel the next command.
+		interrupts <- os.Interrupt
+		for len(interrupts) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		w.Write([]byte("mapb\n"))
+		w.Close()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("prompt didn't return once its input was exhausted")
+		}
+	})
+
+	expected := "Pokedex > \nPokedex > you're on the first page\nPokedex > "
+	if out != expected {
+		t.Errorf("Unexpected prompt output.\n\tExpected: %q\n\tFound: %q", expected, out)
+	}
+}
@@ -0,0 +1,89 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+/* Species command
+ * Takes the name of a Pokemon species and prints its genus, capture rate,
+ * base happiness, growth rate, egg groups, what it evolves from, whether it
+ * is legendary or mythical, and its English Pokedex entry. Prints "Species
+ * not found!" if the pokeapi returns a status code 404.
+ *
+ * Returns an error if the handler fails to coerce the provided arguments as a
+ * string, or if the pokeapi package returns an error.
+ */
+type SpeciesHandler struct{}
+
+func (h SpeciesHandler) Execute(ctx context.Context, params CommandParams) error {
+	speciesName, ok := params.(string)
+	if !ok {
+		return errors.New("Failed type assertion to string. SpeciesHandler requires a string argument")
+	}
+
+	species, err := client.GetPokemonSpecies(ctx, speciesName)
+	if err != nil {
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+			fmt.Println("Species not found!")
+			return err
+		}
+		return fmt.Errorf("Error fetching requested species: %w", err)
+	}
+
+	if genus := englishGenus(species); genus != "" {
+		fmt.Printf("%s, the %s (#%d)\n", species.Name, genus, species.ID)
+	} else {
+		fmt.Printf("%s (#%d)\n", species.Name, species.ID)
+	}
+	fmt.Printf("\t-capture rate: %d\n", species.CaptureRate)
+	fmt.Printf("\t-base happiness: %d\n", species.BaseHappiness)
+	fmt.Printf("\t-growth rate: %s\n", species.GrowthRate.Name)
+
+	eggGroups := make([]string, 0, len(species.EggGroups))
+	for _, group := range species.EggGroups {
+		eggGroups = append(eggGroups, group.Name)
+	}
+	fmt.Printf("\t-egg groups: %s\n", strings.Join(eggGroups, ", "))
+
+	if species.EvolvesFromSpecies.Name != "" {
+		fmt.Printf("\t-evolves from: %s\n", species.EvolvesFromSpecies.Name)
+	}
+	if species.IsLegendary {
+		fmt.Println("\t-legendary")
+	}
+	if species.IsMythical {
+		fmt.Println("\t-mythical")
+	}
+	if text := englishFlavorText(species); text != "" {
+		fmt.Println(text)
+	}
+	return nil
+}
+
+// Returns the species' English genus, e.g. "Mouse Pokémon", or "" if it has
+// none.
+func englishGenus(species pokeapi.PokemonSpecies) string {
+	for _, genus := range species.Genera {
+		if genus.Language.Name == "en" {
+			return genus.Genus
+		}
+	}
+	return ""
+}
+
+// Returns the species' first English Pokedex entry on a single line, or "" if
+// it has none. Entries are wrapped for the games' text boxes with newlines
+// and form feeds, which are collapsed to spaces.
+func englishFlavorText(species pokeapi.PokemonSpecies) string {
+	for _, entry := range species.FlavorTextEntries {
+		if entry.Language.Name == "en" {
+			return strings.Join(strings.Fields(entry.FlavorText), " ")
+		}
+	}
+	return ""
+}
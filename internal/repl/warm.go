@@ -0,0 +1,154 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+const DefaultWarmConcurrency = 8
+
+// WarmStats summarises a Warm crawl.
+type WarmStats struct {
+	LocationAreas int
+	Pokemon       int
+	Failed        int
+}
+
+/* Warm
+ * Crawls every page of location-areas, every location-area, and every
+ * Pokemon encountered in them, so that the client's cache holds everything
+ * map, mapb, explore and catch need. Pages are requested exactly as map
+ * requests them. At most `concurrency` requests are in flight at once, and
+ * progress is written to out.
+ *
+ * Location-areas and Pokemon that fail to fetch are counted and skipped.
+ * Returns an error if listing the location-areas fails or ctx is cancelled.
+ */
+func Warm(ctx context.Context, c *pokeapi.Client, concurrency int, out io.Writer) (stats WarmStats, err error) {
+	// Walk the pages of location-areas
+	var areas []string
+	next := fmt.Sprintf("?offset=0&limit=%d", pageSize)
+	for next != "" {
+		nextURL, err := url.Parse(next)
+		if err != nil {
+			return stats, fmt.Errorf("Error parsing next page url: %w", err)
+		}
+		offset, limit, err := pageParams(nextURL)
+		if err != nil {
+			return stats, err
+		}
+
+		response, err := c.GetLocationAreas(ctx, offset, limit)
+		if err != nil {
+			return stats, fmt.Errorf("Error listing location-areas: %w", err)
+		}
+		for _, locArea := range response.Results {
+			areas = append(areas, locArea.Name)
+		}
+		fmt.Fprintf(out, "\rListing location-areas: %d/%d", len(areas), response.Count)
+		next = response.Next
+	}
+	fmt.Fprintln(out)
+
+	// Explore every location-area, collecting the Pokemon found there
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var pokemon []string
+	stats.Failed += forEachConcurrently(ctx, areas, concurrency, func(ctx context.Context, name string) error {
+		response, err := c.GetLocationArea(ctx, name)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, encounter := range response.PokemonEncounters {
+			if !seen[encounter.Pokemon.Name] {
+				seen[encounter.Pokemon.Name] = true
+				pokemon = append(pokemon, encounter.Pokemon.Name)
+			}
+		}
+		return nil
+	}, func(done int) {
+		fmt.Fprintf(out, "\rExploring location-areas: %d/%d", done, len(areas))
+	})
+	fmt.Fprintln(out)
+	stats.LocationAreas = len(areas)
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+
+	// Fetch every Pokemon found
+	stats.Failed += forEachConcurrently(ctx, pokemon, concurrency, func(ctx context.Context, name string) error {
+		_, err := c.GetPokemon(ctx, name)
+		return err
+	}, func(done int) {
+		fmt.Fprintf(out, "\rFetching Pokemon: %d/%d", done, len(pokemon))
+	})
+	fmt.Fprintln(out)
+	stats.Pokemon = len(pokemon)
+	return stats, ctx.Err()
+}
+
+/* forEachConcurrently
+ * Calls fn on every item, running at most `concurrency` calls at once, and
+ * calls progress with the number of items done after each one finishes.
+ * Stops starting new calls once ctx is cancelled. Returns the number of calls
+ * that failed.
+ */
+func forEachConcurrently(ctx context.Context, items []string, concurrency int,
+	fn func(ctx context.Context, item string) error, progress func(done int)) (failed int) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	slots := make(chan struct{}, max(concurrency, 1))
+
+	for _, item := range items {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return failed
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fn(ctx, item)
+			<-slots
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+			}
+			done++
+			progress(done)
+		}()
+	}
+	wg.Wait()
+	return failed
+}
+
+/* Warm command
+ * Takes no arguments. Crawls every location-area and the Pokemon found in
+ * them into the cache, so later commands don't wait on the network.
+ * Returns an error if the crawl is cancelled or listing location-areas fails.
+ */
+type WarmHandler struct{}
+
+func (h WarmHandler) Execute(ctx context.Context, params CommandParams) error {
+	stats, err := Warm(ctx, client, DefaultWarmConcurrency, os.Stdout)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	fmt.Printf("Warmed %d location-areas and %d Pokemon (%d failed)\n",
+		stats.LocationAreas, stats.Pokemon, stats.Failed)
+	return err
+}
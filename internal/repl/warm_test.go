@@ -0,0 +1,153 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+// Serves 25 location-areas in pages, each with two Pokemon shared between
+// neighbouring areas, and records every path requested.
+func newWarmServer(t *testing.T) (*httptest.Server, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	requested := make(map[string]int)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/location-area", func(w http.ResponseWriter, r *http.Request) {
+		offset, limit, _ := pageParams(r.URL)
+		end := min(offset+limit, 25)
+		next := ""
+		if end < 25 {
+			next = fmt.Sprintf(`"http://%s/location-area?offset=%d&limit=%d"`, r.Host, end, limit)
+		} else {
+			next = "null"
+		}
+		results := ""
+		for i := offset; i < end; i++ {
+			if results != "" {
+				results += ","
+			}
+			results += fmt.Sprintf(`{"name": "area-%d"}`, i)
+		}
+		fmt.Fprintf(w, `{"count": 25, "next": %s, "results": [%s]}`, next, results)
+	})
+	mux.HandleFunc("/location-area/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var i int
+		fmt.Sscanf(r.PathValue("name"), "area-%d", &i)
+		fmt.Fprintf(w, `{"pokemon_encounters": [{"pokemon": {"name": "mon-%d"}}, {"pokemon": {"name": "mon-%d"}}]}`, i, i+1)
+	})
+	mux.HandleFunc("/pokemon/{name}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"name": %q}`, r.PathValue("name"))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path]++
+		mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, requested
+}
+
+func TestWarm(t *testing.T) {
+	server, requested := newWarmServer(t)
+	baseURL, _ := url.Parse(server.URL)
+	c := pokeapi.NewClient(pokeapi.WithBaseURL(baseURL), pokeapi.WithRateLimit(0, 0))
+	defer c.Close()
+
+	stats, err := Warm(context.Background(), c, 4, io.Discard)
+	if err != nil {
+		t.Fatalf("Warm returned an error: %v", err)
+	}
+	expected := WarmStats{LocationAreas: 25, Pokemon: 26}
+	if stats != expected {
+		t.Errorf("Wrong warm stats.\n\tExpected: %+v\n\tFound: %+v", expected, stats)
+	}
+	if requested["/location-area"] != 2 {
+		t.Errorf("Expected 2 pages of location-areas to be listed, found %d", requested["/location-area"])
+	}
+	for path, n := range requested {
+		if n > 1 && path != "/location-area" {
+			t.Errorf("%s was requested %d times", path, n)
+		}
+	}
+
+	// Everything map, explore and catch need is now cached.
+	before := len(requested)
+	if _, err := c.GetLocationAreas(context.Background(), 20, pageSize); err != nil {
+		t.Errorf("GetLocationAreas after warming returned an error: %v", err)
+	}
+	if _, err := c.GetPokemon(context.Background(), "mon-25"); err != nil {
+		t.Errorf("GetPokemon after warming returned an error: %v", err)
+	}
+	if len(requested) != before || requested["/location-area"] != 2 {
+		t.Error("Fetching warmed resources reached the server")
+	}
+}
+
+func TestForEachConcurrentlyLimit(t *testing.T) {
+	const concurrency = 3
+	var mu sync.Mutex
+	running, peak := 0, 0
+	items := make([]string, 20)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// Every call blocks until released, so the calls pile up to the limit.
+	result := make(chan int)
+	go func() {
+		result <- forEachConcurrently(context.Background(), items, concurrency, func(ctx context.Context, item string) error {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+			started <- struct{}{}
+
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return fmt.Errorf("failed")
+		}, func(done int) {})
+	}()
+
+	for range concurrency {
+		<-started
+	}
+	select {
+	case <-started:
+		t.Fatalf("Started more than %d calls at once", concurrency)
+	case <-time.After(50 * time.Millisecond):
+	}
+	mu.Lock()
+	if running != concurrency {
+		t.Errorf("Ran %d calls at once, expected %d", running, concurrency)
+	}
+	mu.Unlock()
+
+	// Let the rest through.
+	close(release)
+	go func() {
+		for range started {
+		}
+	}()
+	failed := <-result
+	close(started)
+
+	if peak != concurrency {
+		t.Errorf("Ran at most %d calls at once, expected %d", peak, concurrency)
+	}
+	if failed != len(items) {
+		t.Errorf("Counted %d failures, expected %d", failed, len(items))
+	}
+}
@@ -0,0 +1,115 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+)
+
+// The 18 types a move can have, in PokeAPI's order.
+var standardTypes = []string{
+	"normal", "fighting", "flying", "poison", "ground", "rock",
+	"bug", "ghost", "steel", "fire", "water", "grass",
+	"electric", "psychic", "ice", "dragon", "dark", "fairy",
+}
+
+// The damage multipliers a Pokemon can take from a move, strongest first.
+var effectivenessBuckets = []float64{4, 2, 1, 0.5, 0.25, 0}
+
+/* effectivenessTable
+ * Damage multipliers indexed by attacking type, then defending type, built
+ * from the damage_relations of the defending types. Pairs that aren't in the
+ * table deal normal damage.
+ */
+type effectivenessTable map[string]map[string]float64
+
+// Records the damage that every attacking type deals to defending.
+func (table effectivenessTable) add(defending pokeapi.Type) {
+	set := func(attacking string, multiplier float64) {
+		if table[attacking] == nil {
+			table[attacking] = make(map[string]float64)
+		}
+		table[attacking][defending.Name] = multiplier
+	}
+
+	relations := defending.DamageRelations
+	for _, attacking := range relations.DoubleDamageFrom {
+		set(attacking.Name, 2)
+	}
+	for _, attacking := range relations.HalfDamageFrom {
+		set(attacking.Name, 0.5)
+	}
+	for _, attacking := range relations.NoDamageFrom {
+		set(attacking.Name, 0)
+	}
+}
+
+// Returns the damage multiplier of an attacking type against a Pokemon with
+// all of the defending types.
+func (table effectivenessTable) multiplier(attacking string, defending []string) float64 {
+	total := 1.0
+	for _, name := range defending {
+		if multiplier, ok := table[attacking][name]; ok {
+			total *= multiplier
+		}
+	}
+	return total
+}
+
+/* Weakness command
+ * Takes the name of a Pokemon, looks up each of its types, and prints the
+ * standard types grouped by how much damage their moves deal to it: 4x, 2x,
+ * 1x, 0.5x, 0.25x and 0x. Empty groups are left out. Prints "Pokemon not
+ * found!" if the pokeapi returns a status code 404.
+ *
+ * Returns an error if the handler fails to coerce the provided arguments as a
+ * string, or if the pokeapi package returns an error.
+ */
+type WeaknessHandler struct{}
+
+func (h WeaknessHandler) Execute(ctx context.Context, params CommandParams) error {
+	pokemonName, ok := params.(string)
+	if !ok {
+		return errors.New("Failed type assertion to string. WeaknessHandler requires a string argument")
+	}
+
+	pokemon, err := client.GetPokemon(ctx, pokemonName)
+	if err != nil {
+		if errors.As(err, &pokeapi.ResourceNotFoundError{}) {
+			fmt.Println("Pokemon not found!")
+			return err
+		}
+		return fmt.Errorf("Error fetching requested Pokemon: %w", err)
+	}
+
+	table := make(effectivenessTable)
+	defending := make([]string, 0, len(pokemon.Types))
+	for _, slot := range pokemon.Types {
+		pokemonType, err := client.GetType(ctx, slot.Type.Name)
+		if err != nil {
+			return fmt.Errorf("Error fetching type %s: %w", slot.Type.Name, err)
+		}
+		table.add(pokemonType)
+		defending = append(defending, pokemonType.Name)
+	}
+
+	buckets := make(map[float64][]string)
+	for _, attacking := range standardTypes {
+		multiplier := table.multiplier(attacking, defending)
+		buckets[multiplier] = append(buckets[multiplier], attacking)
+	}
+
+	fmt.Printf("Damage taken by %s (%s):\n", pokemon.Name, strings.Join(defending, "/"))
+	for _, multiplier := range effectivenessBuckets {
+		if len(buckets[multiplier]) == 0 {
+			continue
+		}
+		fmt.Printf("\t-%sx: %s\n", strconv.FormatFloat(multiplier, 'g', -1, 64),
+			strings.Join(buckets[multiplier], ", "))
+	}
+	return nil
+}
@@ -1,7 +1,149 @@
 package main
 
-import "github.com/caleb-fringer/pokedexcli/internal/repl"
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/caleb-fringer/pokedexcli/internal/pokeapi"
+	"github.com/caleb-fringer/pokedexcli/internal/pokecache"
+	"github.com/caleb-fringer/pokedexcli/internal/repl"
+)
 
 func main() {
-	repl.DoREPL()
+	diskCache := flag.Bool("disk-cache", true, "persist responses between sessions")
+	cacheDir := flag.String("cache-dir", "", "directory for the disk cache (default $XDG_CACHE_HOME/pokedexcli)")
+	diskTTL := flag.Duration("disk-ttl", pokecache.DefaultDiskTTL, "how long responses are kept in the disk cache")
+	maxEntries := flag.Int("cache-max-entries", 0, "maximum number of responses held in memory (0 for unlimited)")
+	maxMB := flag.Int("cache-max-mb", 64, "maximum size of the responses held in memory, in MiB (0 for unlimited)")
+	staleWindow := flag.Duration("stale-window", 24*time.Hour, "serve expired responses for this long while refreshing them in the background (0 to disable)")
+	notFoundTTL := flag.Duration("not-found-ttl", pokeapi.DefaultNotFoundTTL, "remember unknown names for this long (0 to disable)")
+	warm := flag.Bool("warm", false, "fetch every location-area and the Pokemon in them into the cache, then exit")
+	warmConcurrency := flag.Int("warm-concurrency", repl.DefaultWarmConcurrency, "maximum concurrent requests made by -warm")
+	importPath := flag.String("import", "", "load a cache snapshot from this file before the first command")
+	exportPath := flag.String("export", "", "write a snapshot of the cache to this file and exit")
+	recordDir := flag.String("record", "", "record every PokeAPI response to a cassette in this directory")
+	replayDir := flag.String("replay", "", "serve PokeAPI responses from a cassette in this directory instead of the network")
+	flag.Parse()
+
+	if *recordDir != "" && *replayDir != "" {
+		fmt.Fprintln(os.Stderr, "-record and -replay can't be used together")
+		os.Exit(2)
+	}
+	// A disk cache would hide requests from a recording, and let a replay
+	// serve responses that aren't on the cassette.
+	if *recordDir != "" || *replayDir != "" {
+		*diskCache = false
+	}
+
+	cacheOpts := []pokecache.Option{
+		pokecache.WithMaxEntries(*maxEntries),
+		pokecache.WithMaxBytes(*maxMB << 20),
+		pokecache.WithStaleWindow(*staleWindow),
+	}
+	if *diskCache {
+		dir := *cacheDir
+		if dir == "" {
+			var err error
+			if dir, err = pokecache.DefaultDiskDir(); err != nil {
+				fmt.Fprintf(os.Stderr, "Disk cache disabled: %v\n", err)
+			}
+		}
+		if dir != "" {
+			cacheOpts = append(cacheOpts, pokecache.WithDiskTier(dir, *diskTTL))
+		}
+	}
+
+	cache := pokecache.NewCache(pokeapi.DefaultCacheTTL, cacheOpts...)
+	clientOpts := []pokeapi.Option{
+		pokeapi.WithCache(cache),
+		pokeapi.WithStaleWhileRevalidate(*staleWindow),
+		pokeapi.WithNotFoundTTL(*notFoundTTL),
+	}
+	if *recordDir != "" {
+		clientOpts = append(clientOpts, pokeapi.WithRecording(*recordDir))
+	}
+	if *replayDir != "" {
+		clientOpts = append(clientOpts, pokeapi.WithReplay(*replayDir))
+	}
+	client := pokeapi.NewClient(clientOpts...)
+	defer client.Close()
+
+	if *importPath != "" {
+		n, err := cache.ImportFile(*importPath, repl.ImportTTL(*diskTTL))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Imported %d entries from %s\n", n, *importPath)
+	}
+
+	// Set when -warm couldn't fetch everything. The snapshot is still
+	// exported, but the exit status reports that it's incomplete.
+	incomplete := false
+	if *warm {
+		stats, err := repl.Warm(context.Background(), client, *warmConcurrency, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Warmed %d location-areas and %d Pokemon (%d failed)\n",
+			stats.LocationAreas, stats.Pokemon, stats.Failed)
+		if stats.Failed > 0 {
+			fmt.Fprintf(os.Stderr, "Failed to fetch %d resources; the cache is incomplete\n", stats.Failed)
+			incomplete = true
+		}
+	}
+
+	if *exportPath != "" {
+		n, err := cache.ExportFile(*exportPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %d entries to %s\n", n, *exportPath)
+	}
+
+	if incomplete {
+		os.Exit(exitIncomplete)
+	}
+	if *warm || *exportPath != "" {
+		return
+	}
+
+	repl.DoREPL(client, *diskTTL)
+}
+
+// Exit codes for non-interactive runs, so scripts can tell failures apart.
+const (
+	exitFailure     = 1
+	exitNotFound    = 3
+	exitNetwork     = 4
+	exitTimeout     = 5
+	exitRateLimited = 6
+	exitServer      = 7
+	exitDecode      = 8
+	exitIncomplete  = 9 // -warm finished, but some fetches failed
+)
+
+func exitCode(err error) int {
+	switch {
+	case errors.As(err, &pokeapi.ResourceNotFoundError{}):
+		return exitNotFound
+	case errors.As(err, &pokeapi.TimeoutError{}):
+		return exitTimeout
+	case errors.As(err, &pokeapi.NetworkError{}):
+		return exitNetwork
+	case errors.As(err, &pokeapi.RateLimitedError{}):
+		return exitRateLimited
+	case errors.As(err, &pokeapi.ServerError{}):
+		return exitServer
+	case errors.As(err, &pokeapi.DecodeError{}):
+		return exitDecode
+	default:
+		return exitFailure
+	}
 }